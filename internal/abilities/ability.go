@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/oakmound/oak/alg/floatgeom"
 	"github.com/oakmound/oak/collision"
 	"github.com/oakmound/oak/dlog"
 	"github.com/oakmound/oak/entities/x/btn"
@@ -13,6 +14,7 @@ import (
 	"github.com/oakmound/oak/physics"
 	"github.com/oakmound/weekly87/internal/characters"
 	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
 	"github.com/oakmound/weekly87/internal/recolor"
 	"github.com/oakmound/weekly87/internal/sfx"
 
@@ -35,6 +37,15 @@ func Init() {
 	dlog.ErrorCheck(err)
 	rezIcon, err = render.LoadSprite("", filepath.Join("64x64", "RezIcon.png"))
 	dlog.ErrorCheck(err)
+	spearIcon, err = render.LoadSprite("", filepath.Join("64x64", "SpearIcon.png"))
+	dlog.ErrorCheck(err)
+	hourglassIcon, err = render.LoadSprite("", filepath.Join("64x64", "HourglassIcon.png"))
+	dlog.ErrorCheck(err)
+
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Color = render.FontColor("White")
+	fnt.Size = 14
+	chargeFont = fnt.Generate()
 
 	red := color.RGBA{200, 100, 100, 255}
 	blue := color.RGBA{100, 100, 200, 255}
@@ -60,6 +71,9 @@ func Init() {
 	downSlashIcon.Modify(mod.Transpose, mod.Rotate(90))
 	// downSLash.Modify()
 
+	stabIcon = spearIcon.Copy().(*render.Sprite)
+	stabIcon.Modify(mod.FlipY)
+
 	bannerSeq, err = render.LoadSheetSequence(
 		filepath.Join("16x32", "banner.png"),
 		16, 32, 0, 5, []int{0, 0, 1, 0, 2, 0, 3, 0, 0, 1, 1, 1, 2, 1}...)
@@ -72,26 +86,41 @@ func Init() {
 
 	mageInit()
 	WarriorInit()
+	registerBuiltins()
+	builtinUpgrades()
+	// Classes are built from these abilities, so the game can't go on without them
+	if err := loadDefinitions(); err != nil {
+		panic(err)
+	}
+	passiveInit()
 }
 
 var (
 	blastIcon, shieldAuraIcon, shieldIcon, slashIcon, hammerIcon *render.Sprite
 	redBlastIcon, blueBlastIcon, redBlastDIcon, blueBlastDIcon   *render.Sprite
 	upSlashIcon, downSlashIcon, rezIcon, placeHolderBuff         *render.Sprite
+	spearIcon, stabIcon, hourglassIcon                           *render.Sprite
 	bannerSeq                                                    *render.Sequence
+	chargeFont                                                   *render.Font
 	iconW                                                        = 64
 	iconH                                                        = 64
 
 	// BuffIconSize is used to determine how to display the buffs icons
 	BuffIconSize = 16
 
-	dmg     = map[string]float64{"damage": 1.0}
-	baseHit = map[collision.Label]collision.OnHit{
+	dmg     = status.Attack{Damage: 1}
+	baseHit = Attacks(dmg)
+)
+
+// Attacks creates hit effects that attack the enemies they hit.
+// All abilities affect enemies through an attack.
+func Attacks(atk status.Attack) map[collision.Label]collision.OnHit {
+	return map[collision.Label]collision.OnHit{
 		labels.Enemy: func(a, b *collision.Space) {
-			b.CID.Trigger("Attacked", dmg)
+			b.CID.Trigger("Attacked", atk)
 		},
 	}
-)
+}
 
 // User is something that can use abilities
 type User interface {
@@ -109,6 +138,11 @@ type Ability interface {
 	SetUser(User) Ability
 	Enable(bool)
 	SetButton(btn.Btn)
+	Rewind(time.Duration)
+	Killed()
+	// Abilities with a range can be aimed at a point, others fire straight ahead
+	TriggerAt(floatgeom.Point2)
+	Range() float64
 }
 
 type ability struct {
@@ -120,6 +154,36 @@ type ability struct {
 
 	user    User
 	trigger func(User) []characters.Character
+
+	// name the ability is registered under
+	name string
+	// mods from the upgrades the user has bought
+	mods *Modifiers
+
+	// resetOnKill readies the ability again whenever an enemy dies
+	resetOnKill bool
+	// group is shared by abilities that go on cooldown together
+	group string
+	// rng is how far away the ability can be aimed, 0 if it can't be
+	rng float64
+}
+
+// withCharges lets the ability be used n times before it has to recover
+func (a *ability) withCharges(n int) *ability {
+	a.cooldown.charges = newCharges(n, nil)
+	return a
+}
+
+// resetsOnKill readies the ability whenever an enemy dies
+func (a *ability) resetsOnKill() *ability {
+	a.resetOnKill = true
+	return a
+}
+
+// inGroup makes the ability share its cooldown with the user's other abilities in the group
+func (a *ability) inGroup(group string) *ability {
+	a.group = group
+	return a
 }
 
 func (a *ability) SetButton(b btn.Btn) {
@@ -142,12 +206,40 @@ func (a *ability) Trigger() {
 		return
 	}
 
-	artifacts := a.trigger(a.user)
+	artifacts := a.cast(a.user)
 	dlog.Verb("Trigger ability firing")
 	event.Trigger("AbilityFired", artifacts)
 
 }
 
+// caster is the user of an ability as seen by the ability while it is being triggered
+type caster struct {
+	User
+	ability *ability
+}
+
+// ResetCooldown makes the ability that was cast ready to use again
+func (c caster) ResetCooldown() {
+	c.ability.cooldown.ResetTiming()
+}
+
+// A CooldownResetter can have its ability made ready again early
+type CooldownResetter interface {
+	ResetCooldown()
+}
+
+// Rewind the ability's cooldown, as if it had been used earlier than it was
+func (a *ability) Rewind(d time.Duration) {
+	a.cooldown.Rewind(d)
+}
+
+// Killed is called when an enemy dies
+func (a *ability) Killed() {
+	if a.resetOnKill {
+		a.cooldown.ResetTiming()
+	}
+}
+
 // Cooldown gets the total cooldown time  for the ability
 func (a *ability) Cooldown() time.Duration {
 	return a.cooldown.totalTime
@@ -157,12 +249,19 @@ func (a *ability) Cooldown() time.Duration {
 func (a *ability) SetUser(newUser User) Ability {
 	r := a.renderable.Copy().(*render.Switch)
 	cool := r.GetSub("active").(*render.CompositeM).Get(1).(*cooldown)
-	cool.ResetTiming()
+	cool.charges = chargesFor(newUser, a.group, a.cooldown.max)
+	mods := modifiersFor(a.name, newUser)
+	cool.totalTime = time.Duration(float64(cool.totalTime) * scaleOf(mods.CooldownScale))
 	return &ability{
-		renderable: r,
-		cooldown:   cool,
-		user:       newUser,
-		trigger:    a.trigger,
+		renderable:  r,
+		cooldown:    cool,
+		user:        newUser,
+		trigger:     a.trigger,
+		name:        a.name,
+		mods:        &mods,
+		resetOnKill: a.resetOnKill,
+		group:       a.group,
+		rng:         a.rng,
 	}
 }
 
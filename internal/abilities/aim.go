@@ -0,0 +1,116 @@
+package abilities
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/weekly87/internal/sfx"
+)
+
+// aimed is a user as seen by an ability that was aimed at a target
+type aimed struct {
+	User
+	target floatgeom.Point2
+}
+
+// targetOf finds where an ability was aimed, through anything wrapping its user
+func targetOf(u User) (floatgeom.Point2, bool) {
+	dy := 0.0
+	for {
+		switch w := u.(type) {
+		case aimed:
+			return floatgeom.Point2{w.target.X(), w.target.Y() + dy}, true
+		case caster:
+			u = w.User
+		case shifted:
+			// Extra casts keep their distance from each other
+			dy += w.dy
+			u = w.User
+		default:
+			return floatgeom.Point2{}, false
+		}
+	}
+}
+
+// facing is the direction an ability should go, toward its target if it was aimed
+func facing(u User) string {
+	target, ok := targetOf(u)
+	if !ok {
+		return u.Direction()
+	}
+	if target.X() < u.Vec().X() {
+		return "LT"
+	}
+	return "RT"
+}
+
+// Range is how far away an ability can be aimed, 0 if it can't be
+func (a *ability) Range() float64 {
+	return a.rng
+}
+
+// TriggerAt fires the ability toward a target, or straight ahead if it can't be aimed
+func (a *ability) TriggerAt(target floatgeom.Point2) {
+	if a.rng <= 0 {
+		a.Trigger()
+		return
+	}
+	if a.disabled {
+		sfx.Play("nope1")
+		return
+	}
+	if !a.cooldown.Trigger() && !a.user.DebugEnabled() {
+		sfx.Play("cooldown")
+		return
+	}
+
+	artifacts := a.cast(aimed{a.user, target})
+	dlog.Verb("Trigger aimed ability firing")
+	event.Trigger("AbilityFired", artifacts)
+}
+
+// aimedAt turns and stretches a producer's path so it ends at the target,
+// no further than rng from where it starts. Frames are stretched along with the path
+// so the effect keeps its speed.
+func (p Producer) aimedAt(target floatgeom.Point2, rng float64) Producer {
+	end := p.End
+	if n := len(p.ArcPoints); n >= 2 {
+		end = floatgeom.Point2{p.ArcPoints[n-2], p.ArcPoints[n-1]}
+	} else if end == (floatgeom.Point2{}) {
+		// Nothing to aim
+		return p
+	}
+	fromX, fromY := end.X()-p.Start.X(), end.Y()-p.Start.Y()
+	toX, toY := target.X()-p.Start.X(), target.Y()-p.Start.Y()
+	from, to := math.Hypot(fromX, fromY), math.Hypot(toX, toY)
+	if from == 0 || to == 0 {
+		return p
+	}
+	if to > rng {
+		toX, toY = toX*rng/to, toY*rng/to
+		to = rng
+	}
+	// Rotating and scaling the path is multiplying it by a complex number
+	turn := complex(toX, toY) / complex(fromX, fromY)
+	move := func(x, y float64) (float64, float64) {
+		z := complex(x-p.Start.X(), y-p.Start.Y()) * turn
+		return p.Start.X() + real(z), p.Start.Y() + imag(z)
+	}
+	if p.End != (floatgeom.Point2{}) {
+		x, y := move(p.End.X(), p.End.Y())
+		p.End = floatgeom.Point2{x, y}
+	}
+	if len(p.ArcPoints) != 0 {
+		arc := make([]float64, len(p.ArcPoints))
+		for i := 0; i+1 < len(arc); i += 2 {
+			arc[i], arc[i+1] = move(p.ArcPoints[i], p.ArcPoints[i+1])
+		}
+		p.ArcPoints = arc
+	}
+	if p.Frames > 0 {
+		p.Frames = int(math.Max(1, float64(p.Frames)*to/from))
+	}
+	return p
+}
@@ -1,18 +1,30 @@
 package buff
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/oakmound/oak/render"
 	"github.com/oakmound/oak/render/mod"
 )
 
+// A Buff changes a player's status for a while
 type Buff struct {
+	// ID identifies what the buff is. Adding a buff with the same ID as
+	// one the player already has follows the buff's Stacking.
+	ID          ID
+	Name        string
+	Description string
+	Stacking    Stacking
+	// MaxStacks caps how many times an Intensity buff is enabled at once
+	MaxStacks int
+	// Stacks is how many times the buff is enabled
+	Stacks int
+
 	Duration         time.Duration
 	ExpireAt         time.Time
 	Charges          int
 	PreExpireCounter int
-	Name             Name
 	Enable           func(*Status)
 	Disable          func(*Status)
 	R                *render.Switch
@@ -20,18 +32,107 @@ type Buff struct {
 	SinglePlayer     bool
 }
 
-type Name int
+// ID is the identity of a buff
+type ID int
+
+const (
+	// Unidentified buffs never stack, each is added on its own
+	Unidentified ID = iota
+	IDShield
+	IDRez
+	IDInvulnerable
+	IDRage
+	IDHaste
+)
+
+// Stacking is how a buff combines with another of its ID
+type Stacking int
 
 const (
-	unnamed = iota
-	NameShield
-	NameRez
+	// Refresh restarts the buff, keeping the most charges
+	Refresh Stacking = iota
+	// Intensity enables the buff again, up to MaxStacks, and restarts it
+	Intensity
+	// Extend adds the new buff's duration and charges on to the buff
+	Extend
+	// Unique ignores the new buff while the buff lasts
+	Unique
 )
 
+// Merge a newly added buff with the same ID into this one following its stacking.
+// It returns whether Enable should run again for another stack.
+func (b *Buff) Merge(nb Buff, now time.Time) bool {
+	switch b.Stacking {
+	case Refresh, Intensity:
+		if end := now.Add(nb.Duration); end.After(b.ExpireAt) {
+			b.ExpireAt = end
+		}
+		if nb.Charges > b.Charges {
+			b.Charges = nb.Charges
+		}
+		if b.Stacking == Intensity && b.Stacks < b.MaxStacks {
+			b.Stacks++
+			return true
+		}
+	case Extend:
+		b.ExpireAt = b.ExpireAt.Add(nb.Duration)
+		b.Charges += nb.Charges
+	}
+	return false
+}
+
+// End disables every stack of the buff
+func (b Buff) End(s *Status) {
+	for i := 0; i < b.Stacks || i == 0; i++ {
+		b.Disable(s)
+	}
+}
+
+// Tooltip describes the buff and how much of it is left
+func (b Buff) Tooltip(now time.Time) string {
+	tip := b.Name
+	if b.Stacks > 1 {
+		tip += " x" + strconv.Itoa(b.Stacks)
+	}
+	if b.Description != "" {
+		tip += ": " + b.Description
+	}
+	left := b.ExpireAt.Sub(now)
+	if left < 0 {
+		left = 0
+	}
+	tip += " (" + strconv.Itoa(int(left.Seconds()+.5)) + "s"
+	if b.Charges > 0 {
+		tip += ", " + strconv.Itoa(b.Charges) + " charges"
+	}
+	return tip + ")"
+}
+
 type Status struct {
 	Invulnerable int
 	Shield       int
 	Rage int
+	// Haste speeds up the recovery of cooldowns
+	Haste int
+	// Quickened counts the passives hastening the member, which don't stack with each other
+	Quickened int
+	// Swift speeds up running
+	Swift int
+}
+
+// HasteRate is how much faster cooldowns recover for each stack of Haste
+const HasteRate = .5
+
+// SwiftRate is how much faster the party runs for a member with Swift
+const SwiftRate = .15
+
+// CooldownRate is how quickly cooldowns recover, 1 being normal speed
+func (s *Status) CooldownRate() float64 {
+	haste := s.Haste
+	if s.Quickened > 0 {
+		haste++
+	}
+	return 1 + HasteRate*float64(haste)
 }
 
 // BasicBuffSwitch is a utlity that creates our standard flicker setup
@@ -43,7 +144,10 @@ func BasicBuffSwitch(base render.Modifiable) *render.Switch {
 
 func Rage(r render.Modifiable, dur time.Duration) Buff {
 	return Buff{
-		Duration: dur,
+		ID:          IDRage,
+		Name:        "Rage",
+		Description: "Charging forward out of control",
+		Duration:    dur,
 		Enable: func(s *Status) {
 			s.Rage++
 		},
@@ -56,9 +160,33 @@ func Rage(r render.Modifiable, dur time.Duration) Buff {
 	}
 }
 
+func Haste(r render.Modifiable, dur time.Duration) Buff {
+	return Buff{
+		ID:          IDHaste,
+		Name:        "Haste",
+		Description: "Abilities recover faster",
+		Stacking:    Intensity,
+		MaxStacks:   3,
+		Duration:    dur,
+		Enable: func(s *Status) {
+			s.Haste++
+		},
+		Disable: func(s *Status) {
+			s.Haste--
+		},
+		RGen: func() render.Modifiable {
+			return r.Copy()
+		},
+	}
+}
+
 func Invulnerable(r render.Modifiable, dur time.Duration) Buff {
 	return Buff{
-		Duration: dur,
+		ID:          IDInvulnerable,
+		Name:        "Invulnerable",
+		Description: "Enemies can't hurt you",
+		Stacking:    Extend,
+		Duration:    dur,
 		Enable: func(s *Status) {
 			s.Invulnerable++
 		},
@@ -73,7 +201,10 @@ func Invulnerable(r render.Modifiable, dur time.Duration) Buff {
 }
 func Shield(r render.Modifiable, dur time.Duration, charges int, singlePlayer bool) Buff {
 	return Buff{
-		Duration: dur,
+		ID:          IDShield,
+		Name:        "Shield",
+		Description: "Blocks enemies, knocking them back",
+		Duration:    dur,
 		Enable: func(s *Status) {
 			s.Shield++
 		},
@@ -83,12 +214,13 @@ func Shield(r render.Modifiable, dur time.Duration, charges int, singlePlayer bo
 		RGen: func() render.Modifiable {
 			return r.Copy()
 		},
-		Name:         NameShield,
 		Charges:      charges,
 		SinglePlayer: singlePlayer,
 	}
 }
 
 var Rez = Buff{
-	Name: NameRez,
+	ID:       IDRez,
+	Name:     "Rez",
+	Stacking: Unique,
 }
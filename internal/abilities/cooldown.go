@@ -4,39 +4,117 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/timescale"
 )
 
 type cooldown struct {
 	*render.Sprite
-	triggeredTime *time.Time
-	totalTime     time.Duration
+	*charges
+	totalTime time.Duration
+	count     *render.Text
+}
+
+// charges are how many times a cooldown can be triggered before it has to recover.
+// They recover one at a time and can be shared by the abilities of a cooldown group.
+type charges struct {
+	sync.Mutex
+	max       int
+	available int
+	// progress toward recovering the next charge, which takes recharge
+	progress time.Duration
+	recharge time.Duration
+	lastTick time.Time
+	// rate at which the charges recover, from the buffs of the user
+	rate func() float64
+}
+
+func newCharges(max int, rate func() float64) *charges {
+	return &charges{max: max, available: max, rate: rate}
+}
+
+// tick recovers charges for the time passed since the last tick. Callers hold the lock.
+func (ch *charges) tick() {
+	now := timescale.Now()
+	// Game time can be reset between runs, so only ever move forward
+	if passed := now.Sub(ch.lastTick); ch.available < ch.max && !ch.lastTick.IsZero() && passed > 0 {
+		rate := 1.0
+		if ch.rate != nil {
+			rate = ch.rate()
+		}
+		ch.progress += time.Duration(float64(passed) * rate)
+	}
+	ch.lastTick = now
+	ch.settle()
+}
+
+// settle turns progress into charges
+func (ch *charges) settle() {
+	for ch.available < ch.max && ch.progress >= ch.recharge {
+		ch.progress -= ch.recharge
+		ch.available++
+	}
+	if ch.available >= ch.max {
+		ch.progress = 0
+	}
 }
 
 // newCooldown creates a new cooldown
 func newCooldown(w, h int, totalTime time.Duration) *cooldown {
 	s := render.NewEmptySprite(0, 0, w, h)
 
-	return &cooldown{s, &time.Time{}, totalTime}
+	return &cooldown{Sprite: s, charges: newCharges(1, nil), totalTime: totalTime}
 }
 
-// ResetTiming clears the triggered time for a cooldown
+// ResetTiming recovers all of a cooldown's charges
 func (c *cooldown) ResetTiming() {
-	c.triggeredTime = &time.Time{}
+	c.Lock()
+	c.available = c.max
+	c.progress = 0
+	c.Unlock()
 }
 
 // Trigger tries to trigger the cooldown and returns whether it was succesful
 func (c *cooldown) Trigger() bool {
-	if time.Since(*c.triggeredTime) < c.totalTime {
+	c.Lock()
+	defer c.Unlock()
+	c.tick()
+	if c.available <= 0 {
 		return false
 	}
-	// Start the cooldown
-	*c.triggeredTime = time.Now()
+	// Start recovering the charge
+	if c.available == c.max {
+		c.progress = 0
+	}
+	c.available--
+	c.recharge = c.totalTime
 	return true
 }
 
+// Rewind the cooldown as if it had been triggered d earlier
+func (c *cooldown) Rewind(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.tick()
+	if c.available >= c.max {
+		return
+	}
+	c.progress += d
+	c.settle()
+}
+
+// Charges reports how many charges are ready and how many the cooldown holds
+func (c *cooldown) Charges() (int, int) {
+	c.Lock()
+	defer c.Unlock()
+	c.tick()
+	return c.available, c.max
+}
+
 // Draw the cooldown
 func (c *cooldown) Draw(buff draw.Image) {
 	c.DrawOffset(buff, 0, 0)
@@ -44,17 +122,32 @@ func (c *cooldown) Draw(buff draw.Image) {
 
 // DrawOffset draws the cooldown with the given offset
 func (c *cooldown) DrawOffset(buff draw.Image, xOff, yOff float64) {
-	if time.Since(*c.triggeredTime) >= c.totalTime {
+	c.Lock()
+	c.tick()
+	available, max := c.available, c.max
+	percentRecovered := 1.0
+	if c.recharge > 0 {
+		percentRecovered = float64(c.progress) / float64(c.recharge)
+	}
+	c.Unlock()
+
+	if available >= max {
+		if max > 1 {
+			c.drawCount(buff, xOff, yOff, available)
+		}
 		return
 	}
 	// Asset based variables
 	cooldownColor := color.RGBA{125, 125, 125, 125}
+	if available > 0 {
+		// Still usable, only the next charge is recovering
+		cooldownColor = color.RGBA{125, 125, 125, 60}
+	}
 	w, h := c.GetDims()
 	c.Sprite.SetRGBA(image.NewRGBA(image.Rect(0, 0, w, h)))
 	centerX := w / 2
 	centerY := h / 2
 	// Time based variables
-	percentRecovered := float64(time.Since(*c.triggeredTime)) / float64(c.totalTime)
 	cooldownPerimPoints := int((float64(w)*2 + float64(h)*2) * (1 - percentRecovered))
 	pEvaluated := 0
 
@@ -114,10 +207,72 @@ func (c *cooldown) DrawOffset(buff draw.Image, xOff, yOff float64) {
 	}
 End:
 	c.Sprite.DrawOffset(buff, xOff, yOff)
+	if max > 1 {
+		// Keep the count above the sweep
+		c.drawCount(buff, xOff, yOff, available)
+	}
+}
 
+// drawCount shows how many charges are ready in the corner of the cooldown
+func (c *cooldown) drawCount(buff draw.Image, xOff, yOff float64, available int) {
+	if chargeFont == nil {
+		return
+	}
+	if c.count == nil {
+		c.count = chargeFont.NewStrText("", 0, 0)
+	}
+	c.count.SetString(strconv.Itoa(available))
+	w, h := c.GetDims()
+	c.count.DrawOffset(buff, c.X()+xOff+float64(w)-14, c.Y()+yOff+float64(h)-18)
 }
 
 // Copy gets a deep copy of the cooldown
 func (c *cooldown) Copy() render.Modifiable {
-	return &cooldown{c.Sprite.Copy().(*render.Sprite), c.triggeredTime, c.totalTime}
+	return &cooldown{Sprite: c.Sprite.Copy().(*render.Sprite), charges: c.charges, totalTime: c.totalTime}
+}
+
+// A cooldownRater is a user whose buffs change how quickly their cooldowns recover
+type cooldownRater interface {
+	CooldownRate() float64
+}
+
+// groupKey identifies a cooldown group of a single user
+type groupKey struct {
+	user  User
+	group string
+}
+
+var (
+	groupLock sync.Mutex
+	groups    = map[groupKey]*charges{}
+)
+
+// chargesFor gives a user fresh charges, or the charges of the group if the ability
+// shares its cooldown with the user's other abilities
+func chargesFor(u User, group string, max int) *charges {
+	var rate func() float64
+	if r, ok := u.(cooldownRater); ok {
+		// Ask each time, the user's buffs change
+		rate = func() float64 { return r.CooldownRate() }
+	}
+	if group == "" {
+		return newCharges(max, rate)
+	}
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	k := groupKey{u, group}
+	if ch, ok := groups[k]; ok {
+		return ch
+	}
+	ch := newCharges(max, rate)
+	groups[k] = ch
+	return ch
+}
+
+// ResetGroups forgets every cooldown group, run when a new party is made so the
+// groups of past parties don't pile up
+func ResetGroups() {
+	groupLock.Lock()
+	groups = map[groupKey]*charges{}
+	groupLock.Unlock()
 }
@@ -0,0 +1,682 @@
+package abilities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/200sc/go-dist/floatrange"
+	"github.com/200sc/go-dist/intrange"
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/collision"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/fileutil"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+	"github.com/oakmound/oak/render/particle"
+	"github.com/oakmound/oak/shape"
+	"github.com/oakmound/weekly87/internal/abilities/buff"
+	"github.com/oakmound/weekly87/internal/characters"
+	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
+	"github.com/oakmound/weekly87/internal/recolor"
+)
+
+// definitionsFile holds the abilities that are described as data instead of code
+var definitionsFile = filepath.Join("assets", "data", "abilities.json")
+
+// A Definition describes an ability in the definitions file
+type Definition struct {
+	Name     string      `json:"name"`
+	Icon     IconDef     `json:"icon"`
+	Cooldown defDuration `json:"cooldown"`
+	// Charges is how many times the ability can be used before it has to recover, 1 by default
+	Charges int `json:"charges"`
+	// ResetOnKill readies the ability again whenever an enemy dies
+	ResetOnKill bool `json:"resetOnKill"`
+	// Group is shared by abilities that go on cooldown together
+	Group string `json:"group"`
+	// Range lets the ability be aimed at a point up to this far away
+	Range  float64   `json:"range"`
+	Effect EffectDef `json:"effect"`
+	// Upgrades can be bought in the inn, their ids are relative to the ability
+	Upgrades []Upgrade `json:"upgrades"`
+}
+
+// IconDef describes the button shown for an ability
+type IconDef struct {
+	// Image is relative to assets/images
+	Image      string    `json:"image"`
+	Background defColor  `json:"background"`
+	Tint       *defColor `json:"tint"`
+	Rotate     int       `json:"rotate"`
+	FlipY      bool      `json:"flipY"`
+}
+
+// EffectDef describes what an ability produces, mapping onto a Producer.
+// Offsets are written as if the user was facing right and are mirrored when they are not.
+type EffectDef struct {
+	// Origin is what Start, LineTo and ArcTo are relative to, "user" (the default) or "view"
+	Origin   string       `json:"origin"`
+	Start    [2]float64   `json:"start"`
+	LineTo   *[2]float64  `json:"lineTo"`
+	ArcTo    [][2]float64 `json:"arcTo"`
+	Frames   int          `json:"frames"`
+	Duration defDuration  `json:"duration"`
+	// Follow keeps pace with the user, "x" or "xy"
+	Follow    string       `json:"follow"`
+	Sprite    *SpriteDef   `json:"sprite"`
+	Particles *ParticleDef `json:"particles"`
+	Hit       *HitDef      `json:"hit"`
+	Label     string       `json:"label"`
+	Buffs     []BuffDef    `json:"buffs"`
+	Then      *DoDef       `json:"then"`
+	While     *WhileDef    `json:"while"`
+	SFX       string       `json:"sfx"`
+
+	sprite render.Modifiable
+	buffs  []buff.Buff
+}
+
+// SpriteDef describes an image to load. With Frames it is an animation off
+// of a sheet, with W and H it is the first cell of a sheet, otherwise the whole file.
+type SpriteDef struct {
+	// File is relative to assets/images
+	File   string  `json:"file"`
+	W      int     `json:"w"`
+	H      int     `json:"h"`
+	FPS    float64 `json:"fps"`
+	Frames []int   `json:"frames"`
+}
+
+// ParticleDef describes a particle generator. Ranges are [min, max].
+type ParticleDef struct {
+	// Sprite particles are used in place of colored shapes if set
+	Sprite         *SpriteDef  `json:"sprite"`
+	Shape          string      `json:"shape"`
+	StartColor     *defColor   `json:"startColor"`
+	StartColorRand *defColor   `json:"startColorRand"`
+	EndColor       *defColor   `json:"endColor"`
+	EndColorRand   *defColor   `json:"endColorRand"`
+	Angle          *[2]float64 `json:"angle"`
+	Speed          *[2]float64 `json:"speed"`
+	Size           *[2]int     `json:"size"`
+	EndSize        *[2]int     `json:"endSize"`
+	LifeSpan       *[2]float64 `json:"lifeSpan"`
+	PerFrame       *[2]float64 `json:"perFrame"`
+	Spread         [2]float64  `json:"spread"`
+	// ScreenSpread adds this many screen widths to the horizontal spread
+	ScreenSpread float64     `json:"screenSpread"`
+	Gravity      *[2]float64 `json:"gravity"`
+	Pos          *[2]float64 `json:"pos"`
+	// Hit makes particles break on enemies, attacking them
+	Hit *HitDef `json:"hit"`
+
+	sprite *render.Sprite
+}
+
+// HitDef describes what happens to the enemies an effect hits
+type HitDef struct {
+	Damage   float64     `json:"damage"`
+	Pushback float64     `json:"pushback"`
+	Effects  []StatusDef `json:"effects"`
+	// Element is one of fire, frost, force or shield
+	Element string `json:"element"`
+
+	attack status.Attack
+}
+
+// StatusDef describes a status effect put on the enemies an effect hits
+type StatusDef struct {
+	// Kind is one of slow, burn, poison, stun or vulnerable
+	Kind     string      `json:"kind"`
+	Duration defDuration `json:"duration"`
+	// Strength depends on the kind, see status.Effect
+	Strength float64 `json:"strength"`
+}
+
+// BuffDef describes a buff handed to players who pick up an effect
+type BuffDef struct {
+	// Kind is one of shield, invulnerable, rage, haste or rez
+	Kind     string      `json:"kind"`
+	Duration defDuration `json:"duration"`
+	Charges  int         `json:"charges"`
+	Single   bool        `json:"single"`
+	// Color of the buff's icon, defaulting to the placeholder icon
+	Color *defColor `json:"color"`
+	// Description replaces the kind's own in the buff's tooltip
+	Description string `json:"description"`
+}
+
+// DoDef describes what happens where an effect is when it ends
+type DoDef struct {
+	// Drop an effect where this one is
+	Drop *EffectDef `json:"drop"`
+	// Chain an effect whose offsets are relative to where this one is
+	Chain *EffectDef `json:"chain"`
+	Play  string     `json:"play"`
+}
+
+// WhileDef describes what happens repeatedly while an effect lasts
+type WhileDef struct {
+	DoDef
+	Interval defDuration `json:"interval"`
+}
+
+// defDuration is a duration written as a string, like "1.5s"
+type defDuration time.Duration
+
+func (d *defDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = defDuration(dur)
+	return nil
+}
+
+// defColor is a color written as [r, g, b, a]
+type defColor [4]uint8
+
+func (c defColor) rgba() color.RGBA {
+	return color.RGBA{c[0], c[1], c[2], c[3]}
+}
+
+var (
+	defLabels = map[string]collision.Label{
+		"":              0,
+		"effectsEnemy":  labels.EffectsEnemy,
+		"effectsPlayer": labels.EffectsPlayer,
+	}
+	defShapes = map[string]shape.Shape{
+		"diamond": shape.Diamond,
+		"circle":  shape.Circle,
+		"square":  shape.Square,
+	}
+)
+
+// loadDefinitions reads the definitions file and registers each ability in it.
+// Every definition that fails validation is logged, and they are all returned as one error.
+func loadDefinitions() error {
+	rd, err := fileutil.Open(definitionsFile)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+	defs := []Definition{}
+	if err := json.NewDecoder(rd).Decode(&defs); err != nil {
+		return fmt.Errorf("could not read %s: %v", definitionsFile, err)
+	}
+	invalid := []string{}
+	for _, def := range defs {
+		a, err := def.build()
+		if err != nil {
+			dlog.Error("Invalid ability definition", def.Name, err)
+			invalid = append(invalid, fmt.Sprintf("%q: %v", def.Name, err))
+			continue
+		}
+		register(def.Name, a)
+		addUpgrades(def.Name, def.Upgrades...)
+		dlog.Info("Loaded ability definition", def.Name)
+	}
+	if len(invalid) != 0 {
+		return fmt.Errorf("invalid ability definitions in %s: %s", definitionsFile, strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+// build validates a definition, loads what it needs and creates its ability
+func (def Definition) build() (*ability, error) {
+	problems := []string{}
+	if def.Name == "" {
+		problems = append(problems, "missing a name")
+	} else if _, ok := registry[def.Name]; ok {
+		problems = append(problems, "name is already taken")
+	}
+	if def.Cooldown <= 0 {
+		problems = append(problems, "cooldown must be positive")
+	}
+	if def.Charges < 0 {
+		problems = append(problems, "charges can't be negative")
+	}
+	if def.Range < 0 {
+		problems = append(problems, "range can't be negative")
+	} else if def.Range > 0 && def.Effect.LineTo == nil && len(def.Effect.ArcTo) == 0 {
+		problems = append(problems, "only effects with a lineTo or arcTo can be aimed")
+	}
+	icon, err := def.Icon.load()
+	if err != nil {
+		problems = append(problems, "icon: "+err.Error())
+	}
+	problems = append(problems, def.Effect.load("effect")...)
+	seen := map[string]bool{}
+	for i, up := range def.Upgrades {
+		if up.ID == "" || seen[up.ID] {
+			problems = append(problems, fmt.Sprintf("upgrades[%d]: needs an id of its own", i))
+		}
+		if up.Requires != "" && !seen[up.Requires] {
+			problems = append(problems, fmt.Sprintf("upgrades[%d]: requires %q, which has to come before it", i, up.Requires))
+		}
+		if up.Cost <= 0 {
+			problems = append(problems, fmt.Sprintf("upgrades[%d]: cost must be positive", i))
+		}
+		seen[up.ID] = true
+	}
+	if len(problems) != 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+
+	eff := def.Effect
+	a := newAbility(icon, time.Duration(def.Cooldown), func(u User) []characters.Character {
+		pos := u.Vec()
+		origin := floatgeom.Point2{pos.X(), pos.Y()}
+		if eff.Origin == "view" {
+			origin = floatgeom.Point2{float64(oak.ViewPos.X), float64(oak.ViewPos.Y)}
+		}
+		p := eff.producer(u, origin)
+		if target, ok := targetOf(u); ok {
+			p = p.aimedAt(target, def.Range)
+		}
+		chrs, err := p.Produce()
+		dlog.ErrorCheck(err)
+		return chrs
+	})
+	a.rng = def.Range
+	if def.Charges > 1 {
+		a.withCharges(def.Charges)
+	}
+	if def.ResetOnKill {
+		a.resetsOnKill()
+	}
+	return a.inGroup(def.Group), nil
+}
+
+func (ic IconDef) load() (render.Modifiable, error) {
+	sp, err := render.LoadSprite("", filepath.Join(strings.Split(ic.Image, "/")...))
+	if err != nil {
+		return nil, err
+	}
+	sp = sp.Copy().(*render.Sprite)
+	if ic.Tint != nil {
+		sp.Filter(recolor.WithStrategy(recolor.ColorMix(ic.Tint.rgba())))
+	}
+	if ic.Rotate != 0 {
+		sp.Modify(mod.Rotate(ic.Rotate))
+	}
+	if ic.FlipY {
+		sp.Modify(mod.FlipY)
+	}
+	return render.NewCompositeM(render.NewColorBox(iconW, iconH, ic.Background.rgba()), sp), nil
+}
+
+func (sd SpriteDef) load() (render.Modifiable, error) {
+	file := filepath.Join(strings.Split(sd.File, "/")...)
+	if len(sd.Frames) != 0 {
+		if sd.W <= 0 || sd.H <= 0 || sd.FPS <= 0 || len(sd.Frames)%2 != 0 {
+			return nil, errors.New("animations need w, h, fps and pairs of frames")
+		}
+		return render.LoadSheetSequence(file, sd.W, sd.H, 0, sd.FPS, sd.Frames...)
+	}
+	if sd.W > 0 && sd.H > 0 {
+		sheet, err := render.LoadSprites("", file, sd.W, sd.H, 0)
+		if err != nil {
+			return nil, err
+		}
+		return sheet[0][0], nil
+	}
+	return render.LoadSprite("", file)
+}
+
+// load validates an effect and everything it leads to, loading their sprites and buffs
+func (e *EffectDef) load(path string) []string {
+	problems := []string{}
+	bad := func(format string, args ...interface{}) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+	if e.Origin != "" && e.Origin != "user" && e.Origin != "view" {
+		bad("unknown origin %q", e.Origin)
+	}
+	if e.Follow != "" && e.Follow != "x" && e.Follow != "xy" {
+		bad("unknown follow %q", e.Follow)
+	}
+	label, ok := defLabels[e.Label]
+	if !ok {
+		bad("unknown label %q", e.Label)
+	}
+	moves := e.LineTo != nil || len(e.ArcTo) != 0
+	if e.LineTo != nil && len(e.ArcTo) != 0 {
+		bad("lineTo and arcTo can't both be set")
+	}
+	if e.Frames < 0 || e.Duration < 0 {
+		bad("frames and duration can't be negative")
+	}
+	// Things players pick up go away when they are picked up
+	if !moves && e.Duration == 0 && label != labels.EffectsPlayer {
+		bad("never ends, it needs a lineTo, arcTo or duration")
+	}
+	if e.Hit != nil {
+		problems = append(problems, e.Hit.load(path+".hit")...)
+	}
+	if e.Sprite != nil {
+		sp, err := e.Sprite.load()
+		if err != nil {
+			bad("sprite: %v", err)
+		} else {
+			e.sprite = sp
+		}
+	}
+	if e.Particles != nil {
+		problems = append(problems, e.Particles.load(path+".particles")...)
+	}
+	e.buffs = nil
+	for i, bd := range e.Buffs {
+		b, err := bd.buff()
+		if err != nil {
+			bad("buffs[%d]: %v", i, err)
+			continue
+		}
+		e.buffs = append(e.buffs, b)
+	}
+	if e.Then != nil {
+		problems = append(problems, e.Then.load(path+".then")...)
+	}
+	if e.While != nil {
+		if e.While.Interval <= 0 {
+			bad("while needs a positive interval")
+		}
+		problems = append(problems, e.While.load(path+".while")...)
+	}
+	if e.SFX != "" {
+		problems = append(problems, checkSFX(path, e.SFX)...)
+	}
+	return problems
+}
+
+func (pd *ParticleDef) load(path string) []string {
+	problems := []string{}
+	if _, ok := defShapes[pd.Shape]; pd.Shape != "" && !ok {
+		problems = append(problems, fmt.Sprintf("%s: unknown shape %q", path, pd.Shape))
+	}
+	if pd.Hit != nil {
+		problems = append(problems, pd.Hit.load(path+".hit")...)
+	}
+	if pd.Sprite != nil {
+		if len(pd.Sprite.Frames) != 0 {
+			problems = append(problems, path+": particle sprites can't be animated")
+		}
+		sp, err := pd.Sprite.load()
+		if err != nil {
+			problems = append(problems, path+": sprite: "+err.Error())
+		} else {
+			pd.sprite = sp.(*render.Sprite)
+		}
+	}
+	return problems
+}
+
+// load validates a hit and builds the attack it makes
+func (hd *HitDef) load(path string) []string {
+	problems := []string{}
+	hd.attack = status.Attack{Damage: hd.Damage, Pushback: hd.Pushback}
+	if hd.Element != "" {
+		el, ok := status.ElementNamed(hd.Element)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown element %q", path, hd.Element))
+		}
+		hd.attack.Element = el
+	}
+	for i, sd := range hd.Effects {
+		k, ok := status.Named(sd.Kind)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.effects[%d]: unknown kind %q", path, i, sd.Kind))
+			continue
+		}
+		if sd.Duration <= 0 {
+			problems = append(problems, fmt.Sprintf("%s.effects[%d]: duration must be positive", path, i))
+		}
+		if sd.Strength < 0 {
+			problems = append(problems, fmt.Sprintf("%s.effects[%d]: strength can't be negative", path, i))
+		}
+		hd.attack.Effects = append(hd.attack.Effects, status.Effect{
+			Kind:     k,
+			Duration: time.Duration(sd.Duration),
+			Strength: sd.Strength,
+		})
+	}
+	return problems
+}
+
+func (dd *DoDef) load(path string) []string {
+	problems := []string{}
+	if dd.Drop == nil && dd.Chain == nil && dd.Play == "" {
+		problems = append(problems, path+": needs a drop, chain or play")
+	}
+	if dd.Drop != nil {
+		if dd.Drop.Start != [2]float64{} || dd.Drop.LineTo != nil || len(dd.Drop.ArcTo) != 0 {
+			problems = append(problems, path+".drop: dropped effects stay where they land, chain to move on from there")
+		}
+		problems = append(problems, dd.Drop.load(path+".drop")...)
+	}
+	if dd.Chain != nil {
+		problems = append(problems, dd.Chain.load(path+".chain")...)
+	}
+	if dd.Play != "" {
+		problems = append(problems, checkSFX(path, dd.Play)...)
+	}
+	return problems
+}
+
+func (bd BuffDef) buff() (buff.Buff, error) {
+	b, err := bd.kindBuff()
+	if err == nil && bd.Description != "" {
+		b.Description = bd.Description
+	}
+	return b, err
+}
+
+func (bd BuffDef) kindBuff() (buff.Buff, error) {
+	var r render.Modifiable = placeHolderBuff
+	if bd.Color != nil {
+		r = render.NewColorBox(BuffIconSize, BuffIconSize, bd.Color.rgba())
+	}
+	dur := time.Duration(bd.Duration)
+	if bd.Kind != "rez" && dur <= 0 {
+		return buff.Buff{}, errors.New("duration must be positive")
+	}
+	switch bd.Kind {
+	case "shield":
+		if bd.Charges <= 0 {
+			return buff.Buff{}, errors.New("shields need charges")
+		}
+		return buff.Shield(r, dur, bd.Charges, bd.Single), nil
+	case "invulnerable":
+		return buff.Invulnerable(r, dur), nil
+	case "rage":
+		return buff.Rage(r, dur), nil
+	case "haste":
+		return buff.Haste(r, dur), nil
+	case "rez":
+		return buff.Rez, nil
+	}
+	return buff.Buff{}, fmt.Errorf("unknown kind %q", bd.Kind)
+}
+
+// checkSFX makes sure a sound exists, sfx themselves are loaded later on
+func checkSFX(path, s string) []string {
+	rd, err := fileutil.Open(filepath.Join("assets", "audio", s+".wav"))
+	if err != nil {
+		return []string{fmt.Sprintf("%s: unknown sfx %q", path, s)}
+	}
+	rd.Close()
+	return nil
+}
+
+// producer creates the Producer an effect describes for a user, with offsets from origin
+func (e *EffectDef) producer(u User, origin floatgeom.Point2) Producer {
+	flip := facing(u) == "LT"
+	at := func(pt [2]float64) floatgeom.Point2 {
+		if flip {
+			pt[0] *= -1
+		}
+		return origin.Add(floatgeom.Point2{pt[0], pt[1]})
+	}
+
+	opts := []Option{CastBy(u), StartAt(at(e.Start))}
+	if e.LineTo != nil {
+		opts = append(opts, LineTo(at(*e.LineTo)))
+	}
+	if len(e.ArcTo) != 0 {
+		pts := make([]floatgeom.Point2, len(e.ArcTo))
+		for i, pt := range e.ArcTo {
+			pts[i] = at(pt)
+		}
+		opts = append(opts, ArcTo(pts...))
+	}
+	if e.Frames != 0 {
+		opts = append(opts, FrameLength(e.Frames))
+	}
+	if e.Duration != 0 {
+		opts = append(opts, Duration(time.Duration(e.Duration)))
+	}
+	delta := u.GetDelta()
+	switch e.Follow {
+	case "x":
+		opts = append(opts, FollowSpeed(delta.Xp(), nil))
+	case "xy":
+		opts = append(opts, FollowSpeed(delta.Xp(), delta.Yp()))
+	}
+	if e.sprite != nil {
+		r := e.sprite.Copy()
+		if flip {
+			r = r.Modify(mod.FlipX)
+		}
+		opts = append(opts, WithRenderable(r))
+	}
+	if e.Particles != nil {
+		opts = append(opts, WithParticles(e.Particles.generator(flip)))
+	}
+	if e.Hit != nil {
+		opts = append(opts, WithHitEffects(Attacks(e.Hit.attack)))
+	}
+	opts = append(opts, WithLabel(defLabels[e.Label]))
+	for _, b := range e.buffs {
+		opts = append(opts, WithBuff(b))
+	}
+	if e.Then != nil {
+		opts = append(opts, Then(e.Then.do(u)))
+	}
+	if e.While != nil {
+		opts = append(opts, While(e.While.do(u), time.Duration(e.While.Interval)))
+	}
+	if e.SFX != "" {
+		opts = append(opts, PlaySFX(e.SFX))
+	}
+	return And(opts...)(defProducer())
+}
+
+func (dd *DoDef) do(u User) DoOption {
+	dos := []DoOption{}
+	if dd.Drop != nil {
+		dos = append(dos, Drop(dd.Drop.producer(u, floatgeom.Point2{})))
+	}
+	if dd.Chain != nil {
+		dos = append(dos, Chain(dd.Chain.producer(u, floatgeom.Point2{})))
+	}
+	if dd.Play != "" {
+		dos = append(dos, DoPlay(dd.Play))
+	}
+	return AndDo(dos...)
+}
+
+func (pd *ParticleDef) generator(flip bool) particle.Generator {
+	opts := []func(particle.Generator){}
+	if pd.Angle != nil {
+		a := *pd.Angle
+		if flip {
+			a = [2]float64{180 - a[1], 180 - a[0]}
+		}
+		opts = append(opts, particle.Angle(floatRange(a)))
+	}
+	if pd.Speed != nil {
+		opts = append(opts, particle.Speed(floatRange(*pd.Speed)))
+	}
+	if pd.Size != nil {
+		opts = append(opts, particle.Size(intRange(*pd.Size)))
+	}
+	if pd.EndSize != nil {
+		opts = append(opts, particle.EndSize(intRange(*pd.EndSize)))
+	}
+	if pd.LifeSpan != nil {
+		opts = append(opts, particle.LifeSpan(floatRange(*pd.LifeSpan)))
+	}
+	if pd.PerFrame != nil {
+		opts = append(opts, particle.NewPerFrame(floatRange(*pd.PerFrame)))
+	}
+	if spreadX := pd.Spread[0] + float64(oak.ScreenWidth)*pd.ScreenSpread; spreadX != 0 || pd.Spread[1] != 0 {
+		opts = append(opts, particle.Spread(spreadX, pd.Spread[1]))
+	}
+	if pd.Gravity != nil {
+		g := *pd.Gravity
+		if flip {
+			g[0] *= -1
+		}
+		opts = append(opts, particle.Gravity(g[0], g[1]))
+	}
+	if pd.Pos != nil {
+		opts = append(opts, particle.Pos(pd.Pos[0], pd.Pos[1]))
+	}
+	if s, ok := defShapes[pd.Shape]; ok {
+		opts = append(opts, particle.Shape(s))
+	}
+	if pd.StartColor != nil || pd.EndColor != nil {
+		c := func(dc *defColor) color.Color {
+			if dc == nil {
+				return color.RGBA{}
+			}
+			return dc.rgba()
+		}
+		opts = append(opts, particle.Color(c(pd.StartColor), c(pd.StartColorRand), c(pd.EndColor), c(pd.EndColorRand)))
+	}
+
+	var pg particle.Generator
+	if pd.sprite != nil {
+		sp := pd.sprite
+		if flip {
+			sp = sp.Copy().Modify(mod.FlipX).(*render.Sprite)
+		}
+		pg = particle.NewSpriteGenerator(append([]func(particle.Generator){particle.Sprite(sp)}, opts...)...)
+	} else {
+		pg = particle.NewColorGenerator(opts...)
+	}
+	if pd.Hit == nil {
+		return pg
+	}
+	return particle.NewCollisionGenerator(
+		pg,
+		particle.Fragile(true),
+		particle.HitMap(Attacks(pd.Hit.attack)),
+	)
+}
+
+func floatRange(r [2]float64) floatrange.Range {
+	if r[0] == r[1] {
+		return floatrange.NewConstant(r[0])
+	}
+	return floatrange.NewLinear(r[0], r[1])
+}
+
+func intRange(r [2]int) intrange.Range {
+	if r[0] == r[1] {
+		return intrange.NewConstant(r[0])
+	}
+	return intrange.NewLinear(r[0], r[1])
+}
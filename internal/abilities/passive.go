@@ -0,0 +1,155 @@
+package abilities
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/collision"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+	"github.com/oakmound/weekly87/internal/abilities/buff"
+	"github.com/oakmound/weekly87/internal/characters/doodads"
+	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
+	"github.com/oakmound/weekly87/internal/vfx"
+)
+
+// PassiveIconSize is how large passive icons are drawn
+const PassiveIconSize = 24
+
+// A Passive is always on while its holder is alive. It works through the status
+// of the party and a periodic tick instead of being triggered.
+type Passive struct {
+	Name        string
+	Description string
+	Icon        render.Modifiable
+	// PartyWide passives change the status of every member of the party, not just the holder
+	PartyWide bool
+	// Enable and Disable change a status when the passive turns on and off
+	Enable  func(*buff.Status)
+	Disable func(*buff.Status)
+	// Tick runs every Interval for the holder
+	Interval time.Duration
+	Tick     func(User)
+}
+
+var passives = map[string]*Passive{}
+
+// PassiveNamed returns the passive with the given name, or nil if there is none
+func PassiveNamed(name string) *Passive {
+	p, ok := passives[name]
+	if !ok {
+		dlog.Error("No passive named", name)
+		return nil
+	}
+	return p
+}
+
+// passiveIcon is a small icon on a colored background
+func passiveIcon(icon *render.Sprite, bkg color.RGBA) render.Modifiable {
+	scale := float64(PassiveIconSize) / float64(iconW)
+	return render.NewCompositeM(
+		render.NewColorBox(PassiveIconSize, PassiveIconSize, bkg),
+		icon.Copy().Modify(mod.Scale(scale, scale)),
+	)
+}
+
+const (
+	// magnetReach is how far away chests are pulled from, magnetPull how far each tick
+	magnetReach = 200.0
+	magnetPull  = 4.0
+	// thornReach is how close enemies have to be to be hurt by thorns
+	thornReach = 40.0
+)
+
+var thornHit = status.Attack{Damage: 1}
+
+func passiveInit() {
+	for _, p := range []*Passive{
+		{
+			Name:        "Marching Song",
+			Description: "The party runs faster",
+			Icon:        passiveIcon(spearIcon, color.RGBA{60, 160, 60, 255}),
+			PartyWide:   true,
+			Enable: func(s *buff.Status) {
+				s.Swift++
+			},
+			Disable: func(s *buff.Status) {
+				s.Swift--
+			},
+		},
+		{
+			Name:        "Quickening",
+			Description: "The party's abilities recover faster",
+			Icon:        passiveIcon(hourglassIcon, color.RGBA{60, 60, 160, 255}),
+			PartyWide:   true,
+			Enable: func(s *buff.Status) {
+				s.Quickened++
+			},
+			Disable: func(s *buff.Status) {
+				s.Quickened--
+			},
+		},
+		{
+			Name:        "Lodestone",
+			Description: "Chests are pulled toward this adventurer",
+			Icon:        passiveIcon(shieldAuraIcon, color.RGBA{180, 160, 40, 255}),
+			Interval:    time.Second / 30,
+			Tick:        magnet,
+		},
+		{
+			Name:        "Thorns",
+			Description: "Enemies close to this adventurer are hurt every two seconds",
+			Icon:        passiveIcon(slashIcon, color.RGBA{120, 40, 40, 255}),
+			Interval:    2 * time.Second,
+			Tick:        thorns,
+		},
+	} {
+		passives[p.Name] = p
+	}
+}
+
+// magnet pulls nearby chests toward the user
+func magnet(u User) {
+	pos := u.Vec()
+	area := collision.NewUnassignedSpace(pos.X()-magnetReach, pos.Y()-magnetReach, magnetReach*2, magnetReach*2)
+	for _, sp := range collision.Hits(area) {
+		if sp.Label != labels.Chest {
+			continue
+		}
+		ch, ok := sp.CID.E().(*doodads.Chest)
+		if !ok || !ch.Active {
+			continue
+		}
+		ch.SetPos(pull(ch.X(), pos.X()), pull(ch.Y(), pos.Y()))
+	}
+}
+
+// pull moves from toward to by at most magnetPull
+func pull(from, to float64) float64 {
+	if d := to - from; d > magnetPull {
+		return from + magnetPull
+	} else if d < -magnetPull {
+		return from - magnetPull
+	}
+	return to
+}
+
+// thorns hurt the enemies right around the user
+func thorns(u User) {
+	pos := u.Vec()
+	center := floatgeom.Point2{pos.X(), pos.Y()}
+	Produce(
+		StartAt(center),
+		WithParticles(vfx.Burst(color.RGBA{160, 60, 60, 200})),
+		Duration(time.Millisecond*40),
+	)
+	area := collision.NewUnassignedSpace(center.X()-thornReach, center.Y()-thornReach, thornReach*2, thornReach*2)
+	for _, sp := range collision.Hits(area) {
+		if sp.Label == labels.Enemy {
+			sp.CID.Trigger("Attacked", thornHit)
+		}
+	}
+}
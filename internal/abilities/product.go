@@ -18,6 +18,7 @@ import (
 	"github.com/oakmound/weekly87/internal/characters"
 	"github.com/oakmound/weekly87/internal/layer"
 	"github.com/oakmound/weekly87/internal/sfx"
+	"github.com/oakmound/weekly87/internal/timescale"
 )
 
 //Producer of ability affects
@@ -48,6 +49,12 @@ type Producer struct {
 	Arc bool
 
 	Buffs []buff.Buff
+
+	// FiredBy names who fired what is produced, if it was fired at the party
+	FiredBy string
+
+	// mods are the upgrades of the ability producing this
+	mods *Modifiers
 }
 
 // Option to set on the producer
@@ -179,6 +186,15 @@ func Chain(p Producer) DoOption {
 		dlog.Info("Chaining next ability")
 		p.Start = p.Start.Add(pt)
 		p.End = p.End.Add(pt)
+		arc := make([]float64, len(p.ArcPoints))
+		for i, v := range p.ArcPoints {
+			if i%2 == 0 {
+				arc[i] = v + pt.X()
+			} else {
+				arc[i] = v + pt.Y()
+			}
+		}
+		p.ArcPoints = arc
 		chrs, err := p.Produce()
 		if err != nil {
 			dlog.Error(err)
@@ -207,8 +223,7 @@ func Then(do DoOption) Option {
 	}
 }
 
-// While a producer is alive do something
-// Todo: implement while effects on product
+// While a producer is alive do something every interval
 func While(do DoOption, interval time.Duration) Option {
 	return func(p Producer) Producer {
 		p.WhileFn = do
@@ -217,6 +232,22 @@ func While(do DoOption, interval time.Duration) Option {
 	}
 }
 
+// FiredBy names who fired what is produced
+func FiredBy(name string) Option {
+	return func(p Producer) Producer {
+		p.FiredBy = name
+		return p
+	}
+}
+
+// CastBy applies the upgrades of the ability the user is casting, if any
+func CastBy(u User) Option {
+	return func(p Producer) Producer {
+		p.mods = modsOf(u)
+		return p
+	}
+}
+
 // WithBuff sets the buff on the ability
 func WithBuff(b buff.Buff) Option {
 	return func(p Producer) Producer {
@@ -257,10 +288,14 @@ func (p Producer) Produce(opts ...Option) ([]characters.Character, error) {
 	for _, o := range opts {
 		p = o(p)
 	}
+	if p.mods != nil {
+		p = p.mods.apply(p)
+	}
 
 	prd := &Product{
 		Interactive: &entities.Interactive{},
 		next:        p.ThenFn,
+		firedBy:     p.FiredBy,
 	}
 
 	prd.Init()
@@ -271,6 +306,7 @@ func (p Producer) Produce(opts ...Option) ([]characters.Character, error) {
 			return layer.Play
 		})(p.Generator)
 		prd.source = p.Generator.Generate(layer.Play)
+		timescale.TrackSource(prd.source)
 	}
 
 	// Todo: and label?
@@ -351,25 +387,30 @@ func (p Producer) Produce(opts ...Option) ([]characters.Character, error) {
 				dlog.Error("Non product sent to product enter frame")
 				return 0
 			}
-			prd.position++
-			if prd.position >= len(deltas) {
-				prd.Destroy()
-				return event.UnbindSingle
+			// Only move along the path as fast as time is passing here
+			prd.progress += prd.timeAt(floatgeom.Point2{prd.X(), prd.Y()})
+			nextDelta := floatgeom.Point2{*prd.FollowX, *prd.FollowY}
+			for ; prd.progress >= 1; prd.progress-- {
+				prd.position++
+				if prd.position >= len(deltas) {
+					prd.Destroy()
+					return event.UnbindSingle
+				}
+				nextDelta = nextDelta.Add(deltas[prd.position])
 			}
-			nextDelta := deltas[prd.position]
-			prd.Interactive.ShiftPos(nextDelta.X()+*prd.FollowX, nextDelta.Y()+*prd.FollowY)
+			prd.Interactive.ShiftPos(nextDelta.X(), nextDelta.Y())
 			if prd.source != nil {
-				prd.source.ShiftX(nextDelta.X() + *prd.FollowX)
-				prd.source.ShiftY(nextDelta.Y() + *prd.FollowY)
+				prd.source.ShiftX(nextDelta.X())
+				prd.source.ShiftY(nextDelta.Y())
 			}
 			<-prd.Interactive.RSpace.CallOnHits()
 			return 0
 		}, "EnterFrame")
 	}
 	if p.TotalLife != 0 {
-		endTime := time.Now().Add(p.TotalLife)
+		endTime := prd.now().Add(p.TotalLife)
 		prd.Bind(func(id int, _ interface{}) int {
-			if time.Now().After(endTime) {
+			if prd.now().After(endTime) {
 				prd.Destroy()
 				return event.UnbindSingle
 			}
@@ -378,6 +419,23 @@ func (p Producer) Produce(opts ...Option) ([]characters.Character, error) {
 		}, "EnterFrame")
 	}
 
+	if p.WhileFn != nil && p.Interval > 0 {
+		nextTime := prd.now().Add(p.Interval)
+		prd.Bind(func(id int, _ interface{}) int {
+			prd, ok := event.GetEntity(id).(*Product)
+			if !ok {
+				dlog.Error("Non product sent to product enter frame")
+				return 0
+			}
+			if prd.now().Before(nextTime) {
+				return 0
+			}
+			nextTime = nextTime.Add(p.Interval)
+			p.WhileFn(floatgeom.Point2{prd.X(), prd.Y()})
+			return 0
+		}, "EnterFrame")
+	}
+
 	// This might expand later on if things have time limits
 	if p.ThenFn == nil {
 		prd.shouldPersist = true
@@ -407,6 +465,7 @@ type Product struct {
 	*entities.Interactive
 	shouldPersist bool
 	position      int
+	progress      float64
 	TotalLife     time.Duration
 	FollowX       *float64
 	FollowY       *float64
@@ -414,6 +473,25 @@ type Product struct {
 	source *particle.Source
 	next   func(floatgeom.Point2)
 	buffs  []buff.Buff
+	// firedBy names who fired this
+	firedBy string
+}
+
+// now is the game time as the product sees it. What enemies fire at the party
+// keeps to their time, so it stops along with them.
+func (p *Product) now() time.Time {
+	if p.firedBy != "" {
+		return timescale.EnemyNow()
+	}
+	return timescale.Now()
+}
+
+// timeAt is how fast time passes for the product at a point
+func (p *Product) timeAt(pt floatgeom.Point2) float64 {
+	if p.firedBy != "" {
+		return timescale.EnemyAt(pt)
+	}
+	return timescale.At(pt)
 }
 
 // MoveParticles updates the location of the particle source on a product if it exists
@@ -428,11 +506,13 @@ func (p *Product) MoveParticles(nextDelta floatgeom.Point2) {
 func (p *Product) Destroy() {
 	// Note: this assumes that destroys aren't happening simultaneously
 	if p.next != nil {
-		p.next(floatgeom.Point2{p.X(), p.Y()})
+		next := p.next
 		p.next = nil
+		next(floatgeom.Point2{p.X(), p.Y()})
 	}
 	p.Interactive.Destroy()
 	if p.source != nil {
+		timescale.UntrackSource(p.source)
 		p.source.Stop()
 		p.source = nil
 	}
@@ -450,3 +530,8 @@ func (p *Product) ShouldPersist() bool {
 func (p *Product) Buffs() []buff.Buff {
 	return p.buffs
 }
+
+// FiredBy names who fired the product
+func (p *Product) FiredBy() string {
+	return p.firedBy
+}
@@ -0,0 +1,56 @@
+package abilities
+
+import (
+	"sort"
+
+	"github.com/oakmound/oak/dlog"
+)
+
+// registry holds every ability by name, whether it is written in code or defined as data
+var registry = map[string]Ability{}
+
+func register(name string, a *ability) {
+	a.name = name
+	registry[name] = a
+}
+
+// registerBuiltins names the abilities written in code
+func registerBuiltins() {
+	for name, a := range map[string]*ability{
+		"SpearStab":       SpearStab,
+		"SwordSwipe":      SwordSwipe,
+		"HammerSmack":     HammerSmack,
+		"Rage":            Rage,
+		"SpearThrow":      SpearThrow,
+		"PartyShield":     PartyShield,
+		"SelfShield":      SelfShield,
+		"Rez":             Rez,
+		"Invulnerability": Invulnerability,
+		"Slow":            Slow,
+		"CooldownRework":  CooldownRework,
+		"TimeStop":        TimeStop,
+	} {
+		register(name, a)
+	}
+}
+
+// Named returns the ability registered under the given name. Classes can't go
+// without their abilities, so asking for one that doesn't exist panics.
+func Named(name string) Ability {
+	a, ok := registry[name]
+	if !ok {
+		dlog.Error("No ability named", name)
+		panic("no ability named " + name)
+	}
+	return a
+}
+
+// Names lists every registered ability
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
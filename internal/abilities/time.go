@@ -0,0 +1,167 @@
+package abilities
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/200sc/go-dist/floatrange"
+	"github.com/200sc/go-dist/intrange"
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+	"github.com/oakmound/oak/render/particle"
+	"github.com/oakmound/oak/shape"
+	"github.com/oakmound/oak/timing"
+	"github.com/oakmound/weekly87/internal/characters"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/recolor"
+	"github.com/oakmound/weekly87/internal/sfx"
+	"github.com/oakmound/weekly87/internal/timescale"
+)
+
+// Time abilities
+var (
+	Slow, CooldownRework, TimeStop *ability
+)
+
+// Rewind is sent with "CooldownRewind" to rewind the cooldowns of the party's abilities
+type Rewind struct {
+	By time.Duration
+	// Except is the ability that caused the rewind, it does not rewind itself
+	Except Ability
+}
+
+// slowField is a local time field that lasts as long as the product showing it
+type slowField struct {
+	prd   *Product
+	w, h  float64
+	scale float64
+	until time.Time
+}
+
+func (f slowField) Bounds() floatgeom.Rect2 {
+	return floatgeom.NewRect2WH(f.prd.X(), f.prd.Y(), f.w, f.h)
+}
+
+func (f slowField) Scale() float64 {
+	return f.scale
+}
+
+func (f slowField) Done(now time.Time) bool {
+	return now.After(f.until)
+}
+
+// dropSlowField places a field slowing time where a product ends up
+func dropSlowField(w, h, scale float64, dur time.Duration) DoOption {
+	return func(pt floatgeom.Point2) {
+		start := floatgeom.Point2{pt.X() - w/2, pt.Y() - h/2}
+		chrs, err := Produce(
+			StartAt(start),
+			WithRenderable(render.NewColorBox(int(w), int(h), color.RGBA{130, 90, 200, 60})),
+			Duration(dur),
+		)
+		if err != nil {
+			dlog.Error(err)
+			return
+		}
+		timescale.AddField(slowField{
+			prd:   chrs[0].(*Product),
+			w:     w,
+			h:     h,
+			scale: scale,
+			until: timescale.Now().Add(dur),
+		})
+		event.Trigger("AbilityFired", chrs)
+	}
+}
+
+func timeGenerator(c color.RGBA) particle.Generator {
+	return particle.NewColorGenerator(
+		particle.Color(c, color.RGBA{0, 0, 0, 0},
+			color.RGBA{125, 125, 125, 125}, color.RGBA{0, 0, 0, 0}),
+		particle.Shape(shape.Circle),
+		particle.Size(intrange.NewConstant(8)),
+		particle.EndSize(intrange.NewConstant(2)),
+		particle.Speed(floatrange.NewSpread(2, 1)),
+		particle.LifeSpan(floatrange.NewConstant(20)),
+	)
+}
+
+func timeInit() {
+	slowIcon := hourglassIcon.Copy().(*render.Sprite)
+	slowIcon.Filter(recolor.WithStrategy(recolor.ColorMix(color.RGBA{130, 90, 200, 100})))
+	reworkIcon := hourglassIcon.Copy().(*render.Sprite)
+	reworkIcon.Modify(mod.FlipY)
+	stopIcon := hourglassIcon.Copy().(*render.Sprite)
+	stopIcon.Filter(recolor.WithStrategy(recolor.ColorMix(color.RGBA{80, 160, 220, 100})))
+
+	// Slow sends out a mote that becomes a field where time crawls for anything inside
+	Slow = newAbility(
+		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{120, 120, 120, 255}), slowIcon),
+		time.Second*10,
+		func(u User) []characters.Character {
+			pos := u.Vec()
+			endDelta := 300.0
+			if u.Direction() == "LT" {
+				endDelta *= -1
+			}
+			end := floatgeom.Point2{pos.X() + endDelta, pos.Y()}
+			chrs, err := Produce(
+				CastBy(u),
+				StartAt(floatgeom.Point2{pos.X(), pos.Y()}),
+				LineTo(end),
+				FrameLength(30),
+				WithParticles(timeGenerator(color.RGBA{130, 90, 200, 255})),
+				FollowSpeed(u.GetDelta().Xp(), nil),
+				Then(dropSlowField(240, 200, .3, 5*time.Second)),
+				PlaySFX("mageCast1"),
+			)
+			dlog.ErrorCheck(err)
+			return chrs
+		},
+	)
+
+	// CooldownRework turns back the clock on the rest of the party's abilities
+	CooldownRework = newAbility(
+		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{120, 120, 120, 255}), reworkIcon),
+		time.Second*20,
+		func(u User) []characters.Character {
+			rw := Rewind{By: 6 * time.Second}
+			if c, ok := u.(caster); ok {
+				rw.Except = c.ability
+			}
+			event.Trigger("CooldownRewind", rw)
+
+			pos := u.Vec()
+			chrs, err := Produce(
+				CastBy(u),
+				StartAt(floatgeom.Point2{pos.X(), pos.Y()}),
+				WithParticles(timeGenerator(color.RGBA{240, 220, 120, 255})),
+				FollowSpeed(u.GetDelta().Xp(), u.GetDelta().Yp()),
+				Duration(400*time.Millisecond),
+				PlaySFX("mageCast1"),
+			)
+			dlog.ErrorCheck(err)
+			return chrs
+		},
+	)
+
+	// TimeStop briefly freezes enemies and their shots, while the party carries on
+	TimeStop = newAbility(
+		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{120, 120, 120, 255}), stopIcon),
+		time.Second*30,
+		func(u User) []characters.Character {
+			freeze := 2500 * time.Millisecond
+			timescale.Freeze(freeze)
+
+			tint := render.NewColorBox(oak.ScreenWidth, oak.ScreenHeight, color.RGBA{80, 160, 220, 50})
+			render.Draw(tint, layer.UI, 0)
+			timing.DoAfter(freeze, tint.Undraw)
+			sfx.Play("stormEffect")
+			return nil
+		},
+	)
+}
@@ -0,0 +1,203 @@
+package abilities
+
+import (
+	"github.com/oakmound/oak/physics"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+	"github.com/oakmound/weekly87/internal/abilities/buff"
+	"github.com/oakmound/weekly87/internal/characters"
+)
+
+// Modifiers change how an ability behaves once upgrades have been bought for it.
+// Scales of 0 leave things as they are.
+type Modifiers struct {
+	// FrameScale multiplies how many frames an effect takes to travel
+	FrameScale float64 `json:"frameScale"`
+	// SizeScale multiplies the size of an effect's hitbox and sprite
+	SizeScale float64 `json:"sizeScale"`
+	// CooldownScale multiplies the ability's cooldown
+	CooldownScale float64 `json:"cooldownScale"`
+	// ExtraCharges are added to any shields the ability hands out
+	ExtraCharges int `json:"extraCharges"`
+	// ExtraCasts fire the ability again, spread out above and below the user
+	ExtraCasts int `json:"extraCasts"`
+}
+
+func scaleOf(s float64) float64 {
+	if s == 0 {
+		return 1
+	}
+	return s
+}
+
+// and stacks two sets of modifiers
+func (m Modifiers) and(o Modifiers) Modifiers {
+	return Modifiers{
+		FrameScale:    scaleOf(m.FrameScale) * scaleOf(o.FrameScale),
+		SizeScale:     scaleOf(m.SizeScale) * scaleOf(o.SizeScale),
+		CooldownScale: scaleOf(m.CooldownScale) * scaleOf(o.CooldownScale),
+		ExtraCharges:  m.ExtraCharges + o.ExtraCharges,
+		ExtraCasts:    m.ExtraCasts + o.ExtraCasts,
+	}
+}
+
+// apply modifies a producer that is about to produce
+func (m *Modifiers) apply(p Producer) Producer {
+	if m.FrameScale != 0 && p.Frames > 0 {
+		p.Frames = int(float64(p.Frames) * m.FrameScale)
+	}
+	if s := m.SizeScale; s != 0 && s != 1 {
+		if md, ok := p.R.(render.Modifiable); ok {
+			p.R = md.Copy().Modify(mod.Scale(s, s))
+		}
+		if p.W > 1 || p.H > 1 {
+			p.W *= s
+			p.H *= s
+		}
+	}
+	if m.ExtraCharges != 0 {
+		buffs := make([]buff.Buff, len(p.Buffs))
+		copy(buffs, p.Buffs)
+		for i := range buffs {
+			if buffs[i].Charges > 0 {
+				buffs[i].Charges += m.ExtraCharges
+			}
+		}
+		p.Buffs = buffs
+	}
+	return p
+}
+
+// An Upgrade is a node in an ability's upgrade tree
+type Upgrade struct {
+	// ID is unique across all abilities, it is the ability's name and the node's id
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Cost        int    `json:"cost"`
+	// Requires is the ID of the node that has to be bought before this one, if any
+	Requires string    `json:"requires"`
+	Mods     Modifiers `json:"mods"`
+}
+
+// An Upgrader is a user that has bought upgrades for their abilities
+type Upgrader interface {
+	Upgrades() []string
+}
+
+// upgradeTrees holds the upgrades for each ability by name
+var upgradeTrees = map[string][]Upgrade{}
+
+// addUpgrades sets the upgrade tree of an ability, node ids and requirements are
+// given relative to the ability
+func addUpgrades(name string, ups ...Upgrade) {
+	for i := range ups {
+		ups[i].ID = name + "." + ups[i].ID
+		if ups[i].Requires != "" {
+			ups[i].Requires = name + "." + ups[i].Requires
+		}
+	}
+	upgradeTrees[name] = ups
+}
+
+// UpgradesFor lists the upgrades that can be bought for the named ability
+func UpgradesFor(name string) []Upgrade {
+	return upgradeTrees[name]
+}
+
+// NameOf returns the name an ability was registered under
+func NameOf(a Ability) string {
+	ab, ok := a.(*ability)
+	if !ok {
+		return ""
+	}
+	return ab.name
+}
+
+// modifiersFor combines the upgrades a user has bought for the named ability
+func modifiersFor(name string, u User) Modifiers {
+	m := Modifiers{}
+	up, ok := u.(Upgrader)
+	if !ok {
+		return m
+	}
+	bought := map[string]bool{}
+	for _, id := range up.Upgrades() {
+		bought[id] = true
+	}
+	for _, node := range upgradeTrees[name] {
+		if bought[node.ID] {
+			m = m.and(node.Mods)
+		}
+	}
+	return m
+}
+
+// modsOf returns the modifiers of the ability a user is casting, or nil if
+// they aren't casting one
+func modsOf(u User) *Modifiers {
+	c, ok := u.(caster)
+	if !ok {
+		return nil
+	}
+	return c.ability.mods
+}
+
+// castGap is how far apart extra casts are
+const castGap = 24
+
+// shifted is a user as seen by an extra cast, off to the side of where they are
+type shifted struct {
+	User
+	dy float64
+}
+
+func (s shifted) Vec() physics.Vector {
+	return s.User.Vec().Copy().Add(physics.NewVector(0, s.dy))
+}
+
+// cast triggers the ability for u, along with any extra casts from its upgrades
+func (a *ability) cast(u User) []characters.Character {
+	if a.mods == nil {
+		a.mods = &Modifiers{}
+	}
+	artifacts := a.trigger(caster{u, a})
+	for i := 1; i <= a.mods.ExtraCasts; i++ {
+		// Alternate above and below
+		dy := float64((i+1)/2) * castGap
+		if i%2 == 0 {
+			dy *= -1
+		}
+		artifacts = append(artifacts, a.trigger(caster{shifted{u, dy}, a})...)
+	}
+	return artifacts
+}
+
+// builtinUpgrades sets up the upgrade trees of abilities written in code
+func builtinUpgrades() {
+	addUpgrades("SwordSwipe",
+		Upgrade{ID: "broad", Name: "Broadsword", Description: "Swipes a wider area", Cost: 40, Mods: Modifiers{SizeScale: 1.4}},
+		Upgrade{ID: "quick", Name: "Quick Hands", Description: "Swipe more often", Cost: 80, Requires: "broad", Mods: Modifiers{CooldownScale: .75}},
+	)
+	addUpgrades("HammerSmack",
+		Upgrade{ID: "heavy", Name: "Heavier Head", Description: "Smacks a wider area", Cost: 60, Mods: Modifiers{SizeScale: 1.3}},
+		Upgrade{ID: "linger", Name: "Follow Through", Description: "The smack lingers longer", Cost: 90, Requires: "heavy", Mods: Modifiers{FrameScale: 1.5}},
+	)
+	addUpgrades("SpearThrow",
+		Upgrade{ID: "light", Name: "Light Shaft", Description: "Ready to throw sooner", Cost: 60, Mods: Modifiers{CooldownScale: .8}},
+		Upgrade{ID: "pair", Name: "Pair of Spears", Description: "Throw a second spear", Cost: 150, Requires: "light", Mods: Modifiers{ExtraCasts: 1}},
+	)
+	addUpgrades("PartyShield",
+		Upgrade{ID: "sturdy", Name: "Sturdy Banner", Description: "Shields take one more hit", Cost: 70, Mods: Modifiers{ExtraCharges: 1}},
+		Upgrade{ID: "drill", Name: "Shield Drill", Description: "Raise the banner more often", Cost: 120, Requires: "sturdy", Mods: Modifiers{CooldownScale: .8}},
+	)
+	addUpgrades("SelfShield",
+		Upgrade{ID: "sturdy", Name: "Thick Plating", Description: "Shields take two more hits", Cost: 50, Mods: Modifiers{ExtraCharges: 2}},
+	)
+	addUpgrades("Rez",
+		Upgrade{ID: "prayer", Name: "Swift Prayer", Description: "Revive more often", Cost: 120, Mods: Modifiers{CooldownScale: .75}},
+	)
+	addUpgrades("Slow",
+		Upgrade{ID: "patience", Name: "Patience", Description: "Slow time more often", Cost: 80, Mods: Modifiers{CooldownScale: .8}},
+	)
+}
@@ -1,7 +1,7 @@
 package abilities
 
 import (
-	"fmt"
+	"image"
 	"image/color"
 	"path/filepath"
 	"time"
@@ -20,16 +20,13 @@ import (
 	"github.com/oakmound/weekly87/internal/abilities/buff"
 	"github.com/oakmound/weekly87/internal/characters"
 	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
 	"github.com/oakmound/weekly87/internal/recolor"
 	"github.com/oakmound/weekly87/internal/sfx"
 )
 
-func thwack(image string, fLength int, xOffset, yDelta float64, hitEffects map[string]float64, mods ...mod.Mod) func(User) []characters.Character {
-	hits := map[collision.Label]collision.OnHit{
-		labels.Enemy: func(a, b *collision.Space) {
-			b.CID.Trigger("Attacked", hitEffects)
-		},
-	}
+func thwack(image string, fLength int, xOffset, yDelta float64, atk status.Attack, mods ...mod.Mod) func(User) []characters.Character {
+	hits := Attacks(atk)
 	var md render.Modifiable
 	seq, err := render.LoadSheetSequence(image, 32, 32, 0, float64(60/fLength*8),
 		0, 0, 1, 0, 2, 0, 3, 0, 0, 1, 1, 1, 2, 1, 3, 1)
@@ -51,6 +48,7 @@ func thwack(image string, fLength int, xOffset, yDelta float64, hitEffects map[s
 			md = md.Modify(m)
 		}
 		chrs, err := Produce(
+			CastBy(u),
 			StartAt(start),
 			LineTo(start),
 			FrameLength(fLength),
@@ -65,6 +63,49 @@ func thwack(image string, fLength int, xOffset, yDelta float64, hitEffects map[s
 	}
 }
 
+const (
+	stabLength   = 160
+	thrownLength = 48
+)
+
+// spearSprite draws a spear of the given length pointing right
+func spearSprite(length int) *render.Sprite {
+	const h = 6
+	rgba := image.NewRGBA(image.Rect(0, 0, length, h))
+	shaft := color.RGBA{139, 94, 52, 255}
+	tip := color.RGBA{200, 205, 215, 255}
+	tipLength := 10
+	for x := 0; x < length-tipLength; x++ {
+		rgba.Set(x, h/2-1, shaft)
+		rgba.Set(x, h/2, shaft)
+	}
+	for x := length - tipLength; x < length; x++ {
+		spread := (length - x) * (h / 2) / tipLength
+		for y := h/2 - spread; y <= h/2+spread-1; y++ {
+			rgba.Set(x, y, tip)
+		}
+		rgba.Set(x, h/2-1, tip)
+		rgba.Set(x, h/2, tip)
+	}
+	return render.NewSprite(0, 0, rgba)
+}
+
+// catchSpear is done when a thrown spear is picked up. If a party member
+// is the one who picked it up the throw's cooldown is reset.
+func catchSpear(u User) DoOption {
+	return func(pt floatgeom.Point2) {
+		sp := collision.NewUnassignedSpace(pt.X(), pt.Y(), thrownLength, 6)
+		if collision.HitLabel(sp, labels.PC) == nil {
+			// The spear was cleaned up off screen
+			return
+		}
+		if r, ok := u.(CooldownResetter); ok {
+			r.ResetCooldown()
+		}
+		sfx.Play("spearCatch1")
+	}
+}
+
 // Warrior abilities
 var (
 	SpearStab, SwordSwipe, HammerSmack, Rage, SpearThrow, PartyShield, SelfShield *ability
@@ -72,13 +113,33 @@ var (
 
 // WarriorInit run by abilities to set up the ability attributes
 func WarriorInit() {
-	//SpearStab tries to stab in front of the warrior
+	//SpearStab is a long narrow thrust that pierces every enemy in front of the warrior
 	SpearStab = newAbility(
-		render.NewColorBox(64, 64, color.RGBA{200, 200, 0, 255}),
+		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{200, 200, 0, 255}), stabIcon),
 		time.Second*5,
 		func(u User) []characters.Character {
-			fmt.Println("Just tried to stab a guy ", u)
-			return nil
+			pos := u.Vec()
+			r := spearSprite(stabLength)
+			reach := 60.0
+			start := floatgeom.Point2{pos.X() + 16, pos.Y() + 16}
+			if u.Direction() == "LT" {
+				reach *= -1
+				start = floatgeom.Point2{pos.X() - stabLength, pos.Y() + 16}
+				r.Modify(mod.FlipX)
+			}
+			chrs, err := Produce(
+				CastBy(u),
+				StartAt(start),
+				LineTo(floatgeom.Point2{start.X() + reach, start.Y()}),
+				FrameLength(10),
+				FollowSpeed(u.GetDelta().Xp(), u.GetDelta().Yp()),
+				WithHitEffects(baseHit),
+				WithLabel(labels.EffectsEnemy),
+				WithRenderable(r),
+				PlaySFX("spearStab1"),
+			)
+			dlog.ErrorCheck(err)
+			return chrs
 		},
 	)
 
@@ -94,10 +155,10 @@ func WarriorInit() {
 		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{150, 80, 120, 255}), hammerIcon),
 
 		time.Second*8,
-		thwack(filepath.Join("32x32", "BaseSmash.png"), 42, 100, 26, map[string]float64{"damage": 1.0, "pushback": 80.0}, mod.Scale(2, 2)),
+		thwack(filepath.Join("32x32", "BaseSmash.png"), 42, 100, 26, status.Attack{Damage: 1, Pushback: 80, Element: status.Force}, mod.Scale(2, 2)),
 	)
 
-	// Rage is a multistrike attack that impacts party movement
+	// Rage is a multistrike attack that impacts party movement, readied again by every kill
 	Rage = newAbility(
 		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{200, 5, 0, 200}), downSlashIcon),
 		time.Second*5,
@@ -128,6 +189,7 @@ func WarriorInit() {
 			delta := u.GetDelta()
 
 			hit4 := And(
+				CastBy(u),
 				StartAt(floatgeom.Point2{0, -yDelta * 6}),
 				FrameLength(16),
 				FollowSpeed(delta.Xp(), delta.Yp()),
@@ -138,6 +200,7 @@ func WarriorInit() {
 			)(Producer{})
 
 			hit3 := And(
+				CastBy(u),
 				StartAt(floatgeom.Point2{xOffset / 2, 0}),
 				FrameLength(16),
 				FollowSpeed(delta.Xp(), delta.Yp()),
@@ -149,6 +212,7 @@ func WarriorInit() {
 			)(Producer{})
 
 			hit2 := And(
+				CastBy(u),
 				StartAt(floatgeom.Point2{0, yDelta * 5}),
 				FrameLength(16),
 				FollowSpeed(delta.Xp(), delta.Yp()),
@@ -160,6 +224,7 @@ func WarriorInit() {
 			)(Producer{})
 
 			chrs, err := Produce(
+				CastBy(u),
 				StartAt(start),
 				LineTo(start),
 				FrameLength(16),
@@ -174,14 +239,47 @@ func WarriorInit() {
 			event.Trigger("RageStart", nil)
 			return chrs
 		},
-	)
+	).resetsOnKill()
 
+	// SpearThrow arcs a spear ahead of the party that pins the enemies it passes through.
+	// The spear stays where it lands until someone runs over it, which readies it to throw again.
+	pinHit := Attacks(status.Attack{
+		Effects: []status.Effect{{Kind: status.Stun, Duration: 2500 * time.Millisecond}},
+	})
 	SpearThrow = newAbility(
-		render.NewColorBox(64, 64, color.RGBA{200, 50, 150, 255}),
-		time.Second*5,
+		render.NewCompositeM(render.NewColorBox(64, 64, color.RGBA{200, 50, 150, 255}), spearIcon),
+		time.Second*12,
 		func(u User) []characters.Character {
-			fmt.Println("Just tried to spear throw a guy ", u)
-			return nil
+			pos := u.Vec()
+			r := spearSprite(thrownLength)
+			throwDelta := 420.0
+			if u.Direction() == "LT" {
+				throwDelta *= -1
+				r.Modify(mod.FlipX)
+			}
+			start := floatgeom.Point2{pos.X(), pos.Y() + 12}
+			end := floatgeom.Point2{start.X() + throwDelta, start.Y()}
+
+			landed := And(
+				CastBy(u),
+				WithRenderable(r.Copy()),
+				WithLabel(labels.EffectsPlayer),
+				Then(catchSpear(u)),
+			)(Producer{})
+
+			chrs, err := Produce(
+				CastBy(u),
+				StartAt(start),
+				ArcTo(floatgeom.Point2{start.X() + throwDelta/2, start.Y() - 80}, end),
+				FrameLength(40),
+				FollowSpeed(u.GetDelta().Xp(), nil),
+				WithHitEffects(pinHit),
+				WithRenderable(r),
+				Then(Drop(landed)),
+				PlaySFX("spearThrow1"),
+			)
+			dlog.ErrorCheck(err)
+			return chrs
 		},
 	)
 
@@ -195,7 +293,7 @@ func WarriorInit() {
 		func(u User) []characters.Character {
 			pos := u.Vec()
 
-			psBanner := And(WithRenderable(psBannerSeq.Copy()),
+			psBanner := And(CastBy(u), WithRenderable(psBannerSeq.Copy()),
 				WithLabel(labels.EffectsPlayer),
 				WithBuff(buff.Shield(placeHolderBuff, 20*time.Second, 2, false)))(Producer{})
 
@@ -215,6 +313,7 @@ func WarriorInit() {
 			}
 			end := floatgeom.Point2{pos.X() + endDelta, pos.Y()}
 			chrs, err := Produce(
+				CastBy(u),
 				StartAt(floatgeom.Point2{pos.X(), pos.Y()}),
 				//ArcTo(end),
 				LineTo(end),
@@ -237,7 +336,7 @@ func WarriorInit() {
 		func(u User) []characters.Character {
 			pos := u.Vec()
 
-			ssBanner := And(WithRenderable(ssBannerSeq.Copy()),
+			ssBanner := And(CastBy(u), WithRenderable(ssBannerSeq.Copy()),
 				WithLabel(labels.EffectsPlayer),
 				WithBuff(buff.Shield(placeHolderBuff, 20*time.Second, 5, true)))(Producer{})
 
@@ -256,6 +355,7 @@ func WarriorInit() {
 				endDelta *= -1
 			}
 			chrs, err := Produce(
+				CastBy(u),
 				StartAt(floatgeom.Point2{pos.X(), pos.Y()}),
 				LineTo(floatgeom.Point2{pos.X() + endDelta, pos.Y()}),
 				FollowSpeed(u.GetDelta().Xp(), nil),
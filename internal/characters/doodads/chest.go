@@ -20,6 +20,10 @@ type Chest struct {
 	Unmoving
 	Value  int64
 	Active bool
+	// SectionID and Idx locate the chest in its section's entities,
+	// so that picking it up can be remembered
+	SectionID int64
+	Idx       int64
 }
 
 // Init the chest and get its CID
@@ -0,0 +1,149 @@
+package doodads
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/oakmound/oak/entities"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+
+	"github.com/oakmound/weekly87/internal/abilities/buff"
+	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/restrictor"
+)
+
+// A LootKind is something enemies can drop
+type LootKind int
+
+const (
+	// Coins add to the party's wealth
+	Coins LootKind = iota
+	// SmallChest is a chest like any other, it is made with NewChest
+	SmallChest
+	// HasteOrb and ShieldOrb buff the party member who grabs them
+	HasteOrb
+	ShieldOrb
+	// Potion revives a fallen party member
+	Potion
+	LootLimit
+)
+
+var lootNames = [LootLimit]string{
+	Coins:      "coins",
+	SmallChest: "chest",
+	HasteOrb:   "hasteOrb",
+	ShieldOrb:  "shieldOrb",
+	Potion:     "potion",
+}
+
+var lootColors = [LootLimit]color.RGBA{
+	Coins:     {230, 190, 40, 255},
+	HasteOrb:  {220, 220, 60, 255},
+	ShieldOrb: {60, 60, 220, 255},
+	Potion:    {220, 60, 90, 255},
+}
+
+const (
+	orbSize      = 12
+	coinSize     = 8
+	orbBuffLasts = 10 * time.Second
+	orbCharges   = 1
+)
+
+// LootNamed returns the kind of loot with the given name
+func LootNamed(name string) (LootKind, bool) {
+	for k, n := range lootNames {
+		if n == name {
+			return LootKind(k), true
+		}
+	}
+	return 0, false
+}
+
+func (k LootKind) String() string {
+	if k < 0 || k >= LootLimit {
+		return "unknown"
+	}
+	return lootNames[k]
+}
+
+// Loot is something an enemy dropped that the party can pick up by running into it
+type Loot struct {
+	*entities.Reactive
+	Unmoving
+	Kind   LootKind
+	Value  int64
+	Active bool
+	// SectionID and Idx locate the loot in its section's entities,
+	// so that picking it up can be remembered
+	SectionID int64
+	Idx       int64
+}
+
+// Init the loot and get its CID
+func (l *Loot) Init() event.CID {
+	return event.NextID(l)
+}
+
+// Destroy the loot and clean up its artifacts
+func (l *Loot) Destroy() {
+	l.Active = false
+	l.Reactive.Destroy()
+}
+
+// Activate the loot so it can be picked up
+func (l *Loot) Activate() {
+	restrictor.Add(l)
+	l.Active = true
+}
+
+// GetDims of the loot renderable
+func (l *Loot) GetDims() (int, int) {
+	return l.Reactive.R.GetDims()
+}
+
+// Buffs given by orbs and potions
+func (l *Loot) Buffs() []buff.Buff {
+	if !l.Active {
+		return nil
+	}
+	icon := render.NewColorBox(16, 16, lootColors[l.Kind])
+	switch l.Kind {
+	case HasteOrb:
+		return []buff.Buff{singlePlayer(buff.Haste(icon, orbBuffLasts))}
+	case ShieldOrb:
+		return []buff.Buff{buff.Shield(icon, orbBuffLasts, orbCharges, true)}
+	case Potion:
+		return []buff.Buff{buff.Rez}
+	}
+	return nil
+}
+
+// PickedUp takes the loot out of its section for good
+func (l *Loot) PickedUp() {
+	l.Active = false
+	event.Trigger("LootTaken", []int64{l.SectionID, l.Idx})
+}
+
+func singlePlayer(b buff.Buff) buff.Buff {
+	b.SinglePlayer = true
+	return b
+}
+
+// NewLoot creates loot of a kind other than SmallChest, worth some value
+func NewLoot(kind LootKind, value int64) *Loot {
+	l := &Loot{Kind: kind, Value: value}
+	size := orbSize
+	label := labels.EffectsPlayer
+	if kind == Coins {
+		size = coinSize
+		label = labels.Loot
+	}
+	r := render.NewColorBox(size, size, lootColors[kind])
+	r.Modify(mod.CutRound(.5, .5))
+	l.Reactive = entities.NewReactive(0, 0, float64(size), float64(size), r, nil, l.Init())
+	l.RSpace.UpdateLabel(label)
+	return l
+}
@@ -0,0 +1,296 @@
+package enemies
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/physics"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/timescale"
+)
+
+// An Affix makes an enemy an elite
+type Affix int
+
+const (
+	// Shielded enemies shrug off the first few hits
+	Shielded Affix = iota
+	// Splitting enemies split into two smaller ones when they die
+	Splitting
+	// Teleporting enemies blink around every so often
+	Teleporting
+	// Enraged enemies speed up once badly hurt
+	Enraged
+	// Regenerating enemies heal over time
+	Regenerating
+	// Vampiric enemies grow stronger when they kill
+	Vampiric
+	AffixLimit
+)
+
+type affixDef struct {
+	Name string
+	// Color of the marker shown over enemies with the affix
+	Color color.RGBA
+	// MinDepth is how many sections deep the affix starts to roll
+	MinDepth int64
+}
+
+var affixDefs = [AffixLimit]affixDef{
+	Shielded:     {"Shielded", color.RGBA{200, 200, 255, 255}, 5},
+	Splitting:    {"Splitting", color.RGBA{120, 255, 120, 255}, 8},
+	Teleporting:  {"Teleporting", color.RGBA{200, 100, 255, 255}, 10},
+	Enraged:      {"Enraged", color.RGBA{255, 60, 60, 255}, 5},
+	Regenerating: {"Regenerating", color.RGBA{60, 255, 200, 255}, 8},
+	Vampiric:     {"Vampiric", color.RGBA{140, 0, 40, 255}, 12},
+}
+
+// AffixNamed returns the affix with the given name
+func AffixNamed(name string) (Affix, bool) {
+	for a, def := range affixDefs {
+		if def.Name == name {
+			return Affix(a), true
+		}
+	}
+	return 0, false
+}
+
+func (a Affix) String() string {
+	if a < 0 || a >= AffixLimit {
+		return "unknown"
+	}
+	return affixDefs[a].Name
+}
+
+const (
+	// eliteDepth is how many sections deep elites start to show up
+	eliteDepth = 5
+	// maxAffixes is how many affixes one enemy can roll
+	maxAffixes = 3
+
+	shieldCharges = 2
+	// shieldGuard is how long a broken shield keeps protecting
+	shieldGuard   = 500 * time.Millisecond
+	enrageSpeed   = 1.8
+	regenInterval = 3 * time.Second
+	teleportEvery = 3 * time.Second
+	teleportReach = 150.0
+	splitScale    = .6
+	// enraged enemies only rage once worn down to a third of their health
+	enrageBelow = 3
+)
+
+// RollAffixes picks the affixes for an enemy at some depth, if it is an elite.
+// Deeper enemies are elites more often and have more affixes. Only allowed affixes
+// are rolled, unless none are given. odds scales the chance of being an elite.
+func RollAffixes(depth int64, rng *rand.Rand, allowed []Affix, odds float64) []Affix {
+	if depth < eliteDepth {
+		return nil
+	}
+	chance := math.Min(.4, float64(depth-eliteDepth+1)*.03) * odds
+	if rng.Float64() >= chance {
+		return nil
+	}
+	open := []Affix{}
+	if len(allowed) == 0 {
+		for a := Affix(0); a < AffixLimit; a++ {
+			allowed = append(allowed, a)
+		}
+	}
+	for _, a := range allowed {
+		if affixDefs[a].MinDepth <= depth {
+			open = append(open, a)
+		}
+	}
+	count := 1
+	for count < maxAffixes && rng.Float64() < float64(depth)/100 {
+		count++
+	}
+	affixes := []Affix{}
+	for i := 0; i < count && len(open) > 0; i++ {
+		j := rng.Intn(len(open))
+		affixes = append(affixes, open[j])
+		open = append(open[:j], open[j+1:]...)
+	}
+	return affixes
+}
+
+// HasAffix reports whether the enemy has an affix
+func (be *BasicEnemy) HasAffix(a Affix) bool {
+	for _, has := range be.affixes {
+		if has == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Name of the enemy, led by its affixes
+func (be *BasicEnemy) Name() string {
+	words := make([]string, 0, len(be.affixes)+1)
+	for _, a := range be.affixes {
+		words = append(words, a.String())
+	}
+	return strings.Join(append(words, be.name), " ")
+}
+
+// AddAffixes to the enemy, making it an elite
+func (be *BasicEnemy) AddAffixes(affixes ...Affix) {
+	for _, a := range affixes {
+		if a < 0 || a >= AffixLimit || be.HasAffix(a) {
+			continue
+		}
+		be.affixes = append(be.affixes, a)
+		switch a {
+		case Shielded:
+			be.shields = shieldCharges
+		case Regenerating:
+			be.nextRegen = timescale.EnemyNow().Add(regenInterval)
+		case Teleporting:
+			be.nextTeleport = timescale.EnemyNow().Add(teleportEvery)
+		case Vampiric:
+			be.CheckedBind(func(be *BasicEnemy, _ interface{}) int {
+				// Feeding heals and strengthens the enemy
+				be.maxHealth++
+				be.Health = be.maxHealth
+				be.wounds = 0
+				be.flash(affixDefs[Vampiric].Color)
+				return 0
+			}, "Fed")
+		}
+		// Each affix gets a pip over the enemy
+		pip := render.NewColorBox(6, 6, affixDefs[a].Color)
+		pip.Vector = pip.Attach(be.Vector, float64(len(be.pips)*8), -10)
+		be.pips = append(be.pips, pip)
+	}
+}
+
+// drawPips shows the enemy's affix markers
+func (be *BasicEnemy) drawPips() {
+	for _, pip := range be.pips {
+		render.Draw(pip, layer.Play, 2)
+	}
+}
+
+// guarded spends a shield to block a hit, returning whether the hit was blocked
+func (be *BasicEnemy) guarded() bool {
+	if timescale.EnemyNow().Before(be.guardedUntil) {
+		return true
+	}
+	if be.shields <= 0 {
+		return false
+	}
+	be.shields--
+	be.guardedUntil = timescale.EnemyNow().Add(shieldGuard)
+	be.flash(affixDefs[Shielded].Color)
+	if be.shields == 0 {
+		be.dropPip(Shielded)
+	}
+	return true
+}
+
+// dropPip removes the marker of an affix that no longer does anything
+func (be *BasicEnemy) dropPip(a Affix) {
+	for i, has := range be.affixes {
+		if has == a && i < len(be.pips) {
+			be.pips[i].Undraw()
+		}
+	}
+}
+
+// wounded is called when the enemy is hurt and lives
+func (be *BasicEnemy) wounded() {
+	if be.HasAffix(Enraged) && !be.raging && be.Health*enrageBelow <= be.maxHealth {
+		be.raging = true
+		be.baseSpeed.Scale(enrageSpeed)
+		be.Speed.Scale(enrageSpeed)
+		be.flash(affixDefs[Enraged].Color)
+	}
+	if be.HasAffix(Regenerating) {
+		be.nextRegen = timescale.EnemyNow().Add(regenInterval)
+	}
+}
+
+// affixTick runs the affixes that act over time
+func (be *BasicEnemy) affixTick(at time.Time) {
+	if be.HasAffix(Regenerating) && at.After(be.nextRegen) {
+		be.nextRegen = at.Add(regenInterval)
+		if be.Health < be.maxHealth || be.wounds > 0 {
+			be.wounds = 0
+			if be.Health < be.maxHealth {
+				be.Health++
+			}
+			be.flash(affixDefs[Regenerating].Color)
+		}
+	}
+	if be.HasAffix(Teleporting) && at.After(be.nextTeleport) {
+		be.nextTeleport = at.Add(teleportEvery)
+		be.teleport()
+	}
+}
+
+// teleport the enemy somewhere near where it is
+func (be *BasicEnemy) teleport() {
+	if !be.onScreen() {
+		return
+	}
+	be.flash(affixDefs[Teleporting].Color)
+	y := be.Y() + (rand.Float64()*2-1)*teleportReach
+	top, bottom := float64(oak.ScreenHeight)/3, float64(oak.ScreenHeight)-be.H
+	y = math.Max(top, math.Min(bottom, y))
+	be.SetPos(be.X()+(rand.Float64()*2-1)*teleportReach, y)
+	be.flash(affixDefs[Teleporting].Color)
+}
+
+// flash a color over the enemy
+func (be *BasicEnemy) flash(c color.RGBA) {
+	Flash(c)(be)
+}
+
+// Untracked is the index of enemies that aren't one of their section's remembered entities
+const Untracked int64 = -1
+
+// split the enemy into two smaller ones as it dies
+func (be *BasicEnemy) split(secid int64) {
+	if !be.HasAffix(Splitting) || be.cons == nil {
+		return
+	}
+	cons := be.cons.Copy()
+	changeSize(splitScale)(cons)
+	cons.Health = be.maxHealth / 2
+	if cons.Health < 1 {
+		cons.Health = 1
+	}
+	// Splitting only happens once
+	inherited := []Affix{}
+	for _, a := range be.affixes {
+		if a != Splitting {
+			inherited = append(inherited, a)
+		}
+	}
+	spawned := []*BasicEnemy{}
+	for _, dy := range []float64{-20, 20} {
+		// The section doesn't remember children, so they have no index in it
+		child, err := cons.NewEnemy(secid, Untracked)
+		if err != nil {
+			dlog.Error("Failed to split enemy", err)
+			return
+		}
+		child.name = be.name
+		child.AddAffixes(inherited...)
+		if be.facing == "RT" {
+			child.RunBackwards()
+		}
+		child.SetPos(be.X(), be.Y()+dy)
+		child.PushBack(physics.NewVector(10, dy/4))
+		spawned = append(spawned, child)
+	}
+	event.Trigger("EnemiesSpawned", spawned)
+}
@@ -0,0 +1,21 @@
+package enemies
+
+import "testing"
+
+func TestChipHitDoesNotEnrage(t *testing.T) {
+	be := &BasicEnemy{
+		Active:    true,
+		Health:    9,
+		maxHealth: 9,
+		affixes:   []Affix{Enraged},
+	}
+	if be.hurt(1, 0, 0) {
+		t.Fatal("a chip hit killed the enemy")
+	}
+	if be.Health != 8 {
+		t.Fatalf("expected 8 health after a chip hit, got %d", be.Health)
+	}
+	if be.raging {
+		t.Fatal("enemy enraged from a chip hit at full health")
+	}
+}
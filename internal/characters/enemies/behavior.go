@@ -0,0 +1,329 @@
+package enemies
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/collision"
+	"github.com/oakmound/oak/physics"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
+	"github.com/oakmound/weekly87/internal/vfx"
+)
+
+// A State is what an enemy is currently doing
+type State int
+
+const (
+	Idle State = iota
+	Patrol
+	Telegraph
+	Charge
+	Flee
+	Stunned
+	StateLimit
+)
+
+var stateNames = [StateLimit]string{
+	Idle:      "idle",
+	Patrol:    "patrol",
+	Telegraph: "telegraph",
+	Charge:    "charge",
+	Flee:      "flee",
+	Stunned:   "stunned",
+}
+
+func (s State) String() string {
+	if s < 0 || s >= StateLimit {
+		return "unknown"
+	}
+	return stateNames[s]
+}
+
+// A Condition is checked every frame to see if a transition should happen.
+// in is how long the enemy has been in its current state.
+type Condition func(be *BasicEnemy, in time.Duration) bool
+
+// A Transition moves an enemy to another state
+type Transition struct {
+	To State
+	// On is an event that has to have happened to the enemy since the last frame
+	On string
+	// When has to be true, if set
+	When Condition
+}
+
+// A StateDef is how an enemy acts in a state
+type StateDef struct {
+	// Enter runs as the enemy enters the state, Update every frame while it is in it
+	Enter  func(*BasicEnemy)
+	Update func(*BasicEnemy)
+	// Transitions are checked in order, the first that fires is taken
+	Transitions []Transition
+}
+
+// A Behavior is a state machine that drives an enemy
+type Behavior struct {
+	Start  State
+	States map[State]StateDef
+	// Any are transitions checked before those of whatever state the enemy is in
+	Any []Transition
+}
+
+// events lists the events the behavior's transitions wait on
+func (b *Behavior) events() []string {
+	seen := map[string]bool{}
+	evs := []string{}
+	add := func(ts []Transition) {
+		for _, t := range ts {
+			if t.On != "" && !seen[t.On] {
+				seen[t.On] = true
+				evs = append(evs, t.On)
+			}
+		}
+	}
+	add(b.Any)
+	for _, st := range b.States {
+		add(st.Transitions)
+	}
+	return evs
+}
+
+// mind is the state of a behavior for a single enemy
+type mind struct {
+	*Behavior
+	state     State
+	enteredAt time.Time
+	// heard are the events that happened since the last frame
+	heardLock sync.Mutex
+	heard     map[string]bool
+}
+
+// hear that an event happened
+func (m *mind) hear(ev string) {
+	m.heardLock.Lock()
+	m.heard[ev] = true
+	m.heardLock.Unlock()
+}
+
+func newMind(b *Behavior) *mind {
+	return &mind{Behavior: b, heard: map[string]bool{}}
+}
+
+// State returns what the enemy is doing, Idle if it has no behavior
+func (be *BasicEnemy) State() State {
+	if be.mind == nil {
+		return Idle
+	}
+	return be.mind.state
+}
+
+// enter a state, running its Enter
+func (be *BasicEnemy) enter(s State, now time.Time) {
+	be.mind.state = s
+	be.mind.enteredAt = now
+	if st, ok := be.mind.States[s]; ok && st.Enter != nil {
+		st.Enter(be)
+	}
+	be.lead(s)
+}
+
+// think runs the enemy's behavior for a frame
+func (be *BasicEnemy) think(now time.Time) {
+	m := be.mind
+	if m == nil || !be.Active {
+		return
+	}
+	in := now.Sub(m.enteredAt)
+	m.heardLock.Lock()
+	fires := func(ts []Transition) (State, bool) {
+		for _, t := range ts {
+			if t.To == m.state {
+				continue
+			}
+			if t.On != "" && !m.heard[t.On] {
+				continue
+			}
+			if t.When != nil && !t.When(be, in) {
+				continue
+			}
+			return t.To, true
+		}
+		return 0, false
+	}
+	next, ok := fires(m.Any)
+	// Pack members go where their leader takes them, unless they need to shake off a stun
+	if !ok && m.state != Stunned {
+		next, ok = be.ordered()
+	}
+	if !ok && (m.state == Stunned || !be.following()) {
+		next, ok = fires(m.States[m.state].Transitions)
+	}
+	for ev := range m.heard {
+		delete(m.heard, ev)
+	}
+	m.heardLock.Unlock()
+	if ok {
+		be.enter(next, now)
+	}
+	if st := m.States[m.state]; st.Update != nil {
+		st.Update(be)
+	}
+}
+
+// Conditions
+
+// After a state has lasted some time
+func After(d time.Duration) Condition {
+	return func(_ *BasicEnemy, in time.Duration) bool {
+		return in >= d
+	}
+}
+
+// Near the party, within some distance
+func Near(dist float64) Condition {
+	return func(be *BasicEnemy, _ time.Duration) bool {
+		_, ok := be.nearestPC(dist)
+		return ok
+	}
+}
+
+// Far from the party, nothing within some distance
+func Far(dist float64) Condition {
+	near := Near(dist)
+	return func(be *BasicEnemy, in time.Duration) bool {
+		return !near(be, in)
+	}
+}
+
+// IsStunned while a stun is on the enemy
+func IsStunned(be *BasicEnemy, _ time.Duration) bool {
+	return be.statuses.Has(status.Stun)
+}
+
+// All of a set of conditions
+func All(cs ...Condition) Condition {
+	return func(be *BasicEnemy, in time.Duration) bool {
+		for _, c := range cs {
+			if !c(be, in) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Not a condition
+func Not(c Condition) Condition {
+	return func(be *BasicEnemy, in time.Duration) bool {
+		return !c(be, in)
+	}
+}
+
+// Stunnable is the transitions that let any behavior be stunned
+var Stunnable = []Transition{{To: Stunned, When: IsStunned}}
+
+// StunnedState stands still until the stun wears off, then goes back to resume
+func StunnedState(resume State) StateDef {
+	return StateDef{
+		Enter: Stand,
+		Transitions: []Transition{
+			{To: resume, When: Not(IsStunned)},
+		},
+	}
+}
+
+// Actions
+
+// Stand still
+func Stand(be *BasicEnemy) {
+	be.Speed = physics.NewVector(0, 0)
+}
+
+// Walk at the enemy's base speed, the way it is facing
+func Walk(be *BasicEnemy) {
+	be.Speed = be.baseSpeed.Copy()
+	if be.facing == "RT" {
+		be.Speed.Scale(-1)
+	}
+}
+
+// Toward the nearest member of the party at some multiple of the enemy's base speed.
+// Enemies that can't see the party walk instead.
+func Toward(mult float64) func(*BasicEnemy) {
+	return func(be *BasicEnemy) {
+		be.headFor(mult)
+	}
+}
+
+// Away from the nearest member of the party at some multiple of the enemy's base speed
+func Away(mult float64) func(*BasicEnemy) {
+	return func(be *BasicEnemy) {
+		be.headFor(-mult)
+	}
+}
+
+// Flash a burst of color over the enemy, to warn of what it is about to do
+func Flash(c color.RGBA) func(*BasicEnemy) {
+	return func(be *BasicEnemy) {
+		w, h := be.GetDims()
+		abilities.Produce(
+			abilities.StartAt(floatgeom.Point2{be.X() + float64(w)/2, be.Y() + float64(h)/2}),
+			abilities.WithParticles(vfx.Burst(c)),
+			abilities.Duration(time.Millisecond*40),
+		)
+	}
+}
+
+// Both runs each of a set of actions
+func Both(acts ...func(*BasicEnemy)) func(*BasicEnemy) {
+	return func(be *BasicEnemy) {
+		for _, a := range acts {
+			a(be)
+		}
+	}
+}
+
+// sightRange is how far away enemies can find the party from
+const sightRange = 800.0
+
+func (be *BasicEnemy) headFor(mult float64) {
+	pc, ok := be.nearestPC(sightRange)
+	if !ok {
+		Walk(be)
+		return
+	}
+	speed := math.Hypot(be.baseSpeed.X(), be.baseSpeed.Y()) * mult
+	dx, dy := pc.X()-be.X(), pc.Y()-be.Y()
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return
+	}
+	be.Speed = physics.NewVector(dx/d*speed, dy/d*speed)
+}
+
+// nearestPC finds the closest member of the party within some distance
+func (be *BasicEnemy) nearestPC(dist float64) (floatgeom.Point2, bool) {
+	return nearestPC(floatgeom.Point2{be.X(), be.Y()}, dist)
+}
+
+// nearestPC finds the closest member of the party to a point within some distance
+func nearestPC(from floatgeom.Point2, dist float64) (floatgeom.Point2, bool) {
+	area := collision.NewUnassignedSpace(from.X()-dist, from.Y()-dist, dist*2, dist*2)
+	best := math.Inf(1)
+	var pos floatgeom.Point2
+	for _, sp := range collision.Hits(area) {
+		if sp.Label != labels.PC {
+			continue
+		}
+		if d := math.Hypot(sp.X()-from.X(), sp.Y()-from.Y()); d < best && d <= dist {
+			best = d
+			pos = floatgeom.Point2{sp.X(), sp.Y()}
+		}
+	}
+	return pos, !math.IsInf(best, 1)
+}
@@ -0,0 +1,39 @@
+package enemies
+
+import (
+	"strings"
+
+	"github.com/oakmound/oak/event"
+)
+
+// A Sighting is sent along with "EnemySeen" the first time an enemy comes on screen
+type Sighting struct {
+	Type    string
+	Variant string
+	Affixes []string
+	Depth   int64
+}
+
+// sighted lets the bestiary know the enemy has been seen
+func (be *BasicEnemy) sighted(depth int64) {
+	s := Sighting{Type: be.name, Depth: depth}
+	if be.cons != nil {
+		s.Variant = be.cons.Variant
+	}
+	for _, a := range be.affixes {
+		s.Affixes = append(s.Affixes, a.String())
+	}
+	event.Trigger("EnemySeen", s)
+}
+
+// BaseName strips the affixes from the name of an enemy, leaving the name of its kind
+func BaseName(name string) string {
+	words := strings.Fields(name)
+	for len(words) > 1 {
+		if _, ok := AffixNamed(words[0]); !ok {
+			break
+		}
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
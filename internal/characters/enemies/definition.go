@@ -0,0 +1,435 @@
+package enemies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/fileutil"
+	"github.com/oakmound/oak/physics"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+	"github.com/oakmound/weekly87/internal/recolor"
+	"github.com/solovev/gopsd"
+)
+
+// definitionsFile holds the kinds of enemies
+var definitionsFile = filepath.Join("assets", "data", "enemies.json")
+
+// A Definition describes a kind of enemy in the definitions file
+type Definition struct {
+	Name string `json:"name"`
+	// Sheet is relative to assets/images. The layers of .psd sheets are flattened.
+	Sheet string `json:"sheet"`
+	// Overlay keeps all but the last layer of a .psd sheet out of the sheet,
+	// combining them with every frame without being recolored by variants
+	Overlay bool `json:"overlay"`
+	// Tint is mixed into the sheet, BaseTint only into the plain variant
+	Tint     *[4]uint8 `json:"tint"`
+	BaseTint *[4]uint8 `json:"baseTint"`
+	FrameW   int       `json:"frameW"`
+	FrameH   int       `json:"frameH"`
+	FPS      float64   `json:"fps"`
+	// Animations are the [x, y] cells of the sheet in each frame of each animation.
+	// standRT and walkRT are required, the LT animations mirror them if left out.
+	Animations  map[string][][2]int `json:"animations"`
+	Dimensions  [2]float64          `json:"dimensions"`
+	SpaceOffset [2]float64          `json:"spaceOffset"`
+	Speed       [2]float64          `json:"speed"`
+	// SpeedRand is added to Speed, scaled by a random amount from 0 to 1 as the game starts
+	SpeedRand [2]float64 `json:"speedRand"`
+	Health    int        `json:"health"`
+	// Behavior is the name of the behavior driving the enemy
+	Behavior string `json:"behavior"`
+	// Colors, Sizes and Affixes are those allowed, all of them if left out
+	Colors  []string `json:"colors"`
+	Sizes   []string `json:"sizes"`
+	Affixes []string `json:"affixes"`
+	// Drops are what the plain variant can leave behind, other variants scale them
+	Drops []DropDef `json:"drops"`
+	// Pursuit is how fast the enemy chases the party as it runs back, if it does
+	Pursuit float64 `json:"pursuit"`
+	// Notes describe the enemy in the bestiary
+	Notes string `json:"notes"`
+}
+
+var (
+	// behaviors can be named by definitions
+	behaviors = map[string]*Behavior{
+		"hop":           hareBehavior,
+		"charge":        mantisBehavior,
+		"stand":         treeBehavior,
+		"shootStraight": rangedBehavior(Straight),
+		"shootArcing":   rangedBehavior(Arcing),
+		"shootHoming":   rangedBehavior(Homing),
+	}
+	colorNames = map[string]int{
+		"base":   baseColor,
+		"blue":   blueColor,
+		"red":    redColor,
+		"black":  blackColor,
+		"purple": purpleColor,
+	}
+	sizeNames = map[string]int{
+		"base":  baseSize,
+		"large": largeSize,
+		"small": smallSize,
+		"giant": giantSize,
+	}
+)
+
+var (
+	// TypeLimit is how many kinds of enemies there are
+	TypeLimit int
+	// Constructors hold every variant of every kind of enemy, VariantCount for each kind
+	Constructors []*Constructor
+	typeNames    []string
+	typeNotes    []string
+)
+
+// TypeNamed returns the kind of enemy with the given name
+func TypeNamed(name string) (int, bool) {
+	for i, n := range typeNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// TypeName returns the name of a kind of enemy
+func TypeName(typ int) string {
+	if typ < 0 || typ >= len(typeNames) {
+		return ""
+	}
+	return typeNames[typ]
+}
+
+// TypeNotes returns what the bestiary says about a kind of enemy
+func TypeNotes(typ int) string {
+	if typ < 0 || typ >= len(typeNotes) {
+		return ""
+	}
+	return typeNotes[typ]
+}
+
+// variantName describes a variant by its size and color, leaving out the plain ones
+func variantName(size, col int) string {
+	words := []string{}
+	for name, s := range sizeNames {
+		if s == size && s != baseSize {
+			words = append(words, name)
+		}
+	}
+	for name, c := range colorNames {
+		if c == col && c != baseColor {
+			words = append(words, name)
+		}
+	}
+	if len(words) == 0 {
+		return "plain"
+	}
+	return strings.Join(words, " ")
+}
+
+// loadDefinitions reads the definitions file and sets up the constructors for each kind of enemy in it.
+// Every definition that fails validation is logged, and they are all returned as one error.
+func loadDefinitions() error {
+	rd, err := fileutil.Open(definitionsFile)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+	defs := []Definition{}
+	if err := json.NewDecoder(rd).Decode(&defs); err != nil {
+		return fmt.Errorf("could not read %s: %v", definitionsFile, err)
+	}
+	Constructors = make([]*Constructor, 0, len(defs)*VariantCount)
+	typeNames = make([]string, 0, len(defs))
+	typeNotes = make([]string, 0, len(defs))
+	invalid := []string{}
+	for _, def := range defs {
+		variants, err := def.build()
+		if err != nil {
+			dlog.Error("Invalid enemy definition", def.Name, err)
+			invalid = append(invalid, fmt.Sprintf("%q: %v", def.Name, err))
+			continue
+		}
+		Constructors = append(Constructors, variants...)
+		typeNames = append(typeNames, def.Name)
+		typeNotes = append(typeNotes, def.Notes)
+		dlog.Info("Loaded enemy definition", def.Name)
+	}
+	TypeLimit = len(typeNames)
+	if len(invalid) != 0 {
+		return fmt.Errorf("invalid enemy definitions in %s: %s", definitionsFile, strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+// build validates a definition and creates a constructor for each of its variants.
+// Variants that aren't allowed fall back to the plain color or size.
+func (def Definition) build() ([]*Constructor, error) {
+	problems := []string{}
+	if def.Name == "" {
+		problems = append(problems, "missing a name")
+	} else if _, ok := TypeNamed(def.Name); ok {
+		problems = append(problems, "name is already taken")
+	}
+	if def.FrameW <= 0 || def.FrameH <= 0 {
+		problems = append(problems, "frameW and frameH must be positive")
+	}
+	if def.Dimensions[0] <= 0 || def.Dimensions[1] <= 0 {
+		problems = append(problems, "dimensions must be positive")
+	}
+	if def.Health <= 0 {
+		problems = append(problems, "health must be positive")
+	}
+	behavior, ok := behaviors[def.Behavior]
+	if !ok {
+		problems = append(problems, fmt.Sprintf("unknown behavior %q", def.Behavior))
+	}
+	colors, err := allowed(def.Colors, colorNames, lastColor)
+	if err != nil {
+		problems = append(problems, "colors: "+err.Error())
+	}
+	sizes, err := allowed(def.Sizes, sizeNames, lastSize)
+	if err != nil {
+		problems = append(problems, "sizes: "+err.Error())
+	}
+	affixes, err := def.allowedAffixes()
+	if err != nil {
+		problems = append(problems, "affixes: "+err.Error())
+	}
+	drops, err := def.drops()
+	if err != nil {
+		problems = append(problems, "drops: "+err.Error())
+	}
+	anims, overlay, err := def.load()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) != 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+
+	baseConstructor := Constructor{
+		Name:         def.Name,
+		Dimensions:   floatgeom.Point2{def.Dimensions[0], def.Dimensions[1]},
+		AnimationMap: anims,
+		Speed: floatgeom.Point2{
+			def.Speed[0] + rand.Float64()*def.SpeedRand[0],
+			def.Speed[1] + rand.Float64()*def.SpeedRand[1],
+		},
+		Health:   def.Health,
+		Behavior: behavior,
+		Affixes:  affixes,
+		Drops:    drops,
+		Pursuit:  def.Pursuit,
+	}
+	if def.SpaceOffset != [2]float64{} {
+		baseConstructor.SpaceOffset = physics.NewVector(def.SpaceOffset[0], def.SpaceOffset[1])
+	}
+
+	variants := make([]*Constructor, VariantCount)
+	for size := 0; size < lastSize; size++ {
+		for col := 0; col < lastColor; col++ {
+			if !sizes[size] || !colors[col] {
+				continue
+			}
+			cons := baseConstructor.Copy()
+			colorVariants[col](cons)
+			if size == baseSize && col == baseColor && def.BaseTint != nil {
+				for _, md := range cons.AnimationMap {
+					md.Filter(recolor.WithStrategy(recolor.ColorMix(rgba(*def.BaseTint))))
+				}
+			}
+			if overlay != nil {
+				cons.overlay(overlay)
+			}
+			sizeVariants[size](cons)
+			cons.scaleDrops(size, col)
+			cons.Variant = variantName(size, col)
+			variants[size*lastColor+col] = cons
+		}
+	}
+	for size := 0; size < lastSize; size++ {
+		for col := 0; col < lastColor; col++ {
+			if variants[size*lastColor+col] != nil {
+				continue
+			}
+			fallback := variants[size*lastColor+baseColor]
+			if fallback == nil {
+				fallback = variants[baseSize*lastColor+col]
+			}
+			if fallback == nil {
+				fallback = variants[baseSize*lastColor+baseColor]
+			}
+			variants[size*lastColor+col] = fallback
+		}
+	}
+	return variants, nil
+}
+
+// allowed turns a list of names into which of them are allowed, all by default
+func allowed(names []string, known map[string]int, limit int) ([]bool, error) {
+	ok := make([]bool, limit)
+	if len(names) == 0 {
+		for i := range ok {
+			ok[i] = true
+		}
+		return ok, nil
+	}
+	for _, name := range names {
+		i, found := known[name]
+		if !found {
+			return nil, fmt.Errorf("unknown %q", name)
+		}
+		ok[i] = true
+	}
+	// Everything falls back to the plain variant, so it has to be there
+	if !ok[0] {
+		return nil, errors.New("base has to be allowed")
+	}
+	return ok, nil
+}
+
+func (def Definition) allowedAffixes() ([]Affix, error) {
+	if len(def.Affixes) == 0 {
+		return nil, nil
+	}
+	affixes := []Affix{}
+	for _, name := range def.Affixes {
+		a, ok := AffixNamed(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown %q", name)
+		}
+		affixes = append(affixes, a)
+	}
+	return affixes, nil
+}
+
+// load the animations of the definition, and the overlay drawn over them if it has one
+func (def Definition) load() (map[string]render.Modifiable, *render.CompositeM, error) {
+	file := filepath.Join("assets", "images", filepath.Join(strings.Split(def.Sheet, "/")...))
+	var sheet [][]*render.Sprite
+	var overlay *render.CompositeM
+	var err error
+	if strings.HasSuffix(def.Sheet, ".psd") {
+		sheet, overlay, err = loadPSD(file, def.FrameW, def.FrameH, def.Overlay)
+	} else {
+		sheet, err = render.LoadSprites(filepath.Join("assets", "images"),
+			filepath.Join(strings.Split(def.Sheet, "/")...), def.FrameW, def.FrameH, 0)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	cell := func(xy [2]int) (*render.Sprite, error) {
+		if xy[0] < 0 || xy[0] >= len(sheet) || xy[1] < 0 || xy[1] >= len(sheet[xy[0]]) {
+			return nil, fmt.Errorf("cell %v is off the sheet", xy)
+		}
+		return sheet[xy[0]][xy[1]].Copy().(*render.Sprite), nil
+	}
+	anims := map[string]render.Modifiable{}
+	for _, name := range []string{"standRT", "walkRT", "standLT", "walkLT"} {
+		frames, ok := def.Animations[name]
+		if !ok {
+			if strings.HasSuffix(name, "LT") {
+				anims[name] = anims[strings.TrimSuffix(name, "LT")+"RT"].Copy().Modify(mod.FlipX)
+				continue
+			}
+			return nil, nil, fmt.Errorf("animation %s is required", name)
+		}
+		if len(frames) == 0 {
+			return nil, nil, fmt.Errorf("animation %s has no frames", name)
+		}
+		sps := make([]render.Modifiable, len(frames))
+		for i, xy := range frames {
+			if sps[i], err = cell(xy); err != nil {
+				return nil, nil, fmt.Errorf("animation %s: %v", name, err)
+			}
+		}
+		if len(sps) == 1 {
+			anims[name] = sps[0]
+			continue
+		}
+		if def.FPS <= 0 {
+			return nil, nil, fmt.Errorf("animation %s has frames but no fps", name)
+		}
+		anims[name] = render.NewSequence(def.FPS, sps...)
+	}
+	if def.Tint != nil {
+		for _, md := range anims {
+			md.Filter(recolor.WithStrategy(recolor.ColorMix(rgba(*def.Tint))))
+		}
+	}
+	return anims, overlay, nil
+}
+
+// loadPSD flattens the layers of a psd into a sheet. With overlay, only the last
+// layer goes into the sheet, the rest are returned to be drawn over it.
+func loadPSD(file string, w, h int, overlay bool) ([][]*render.Sprite, *render.CompositeM, error) {
+	rd, err := fileutil.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rd.Close()
+	data, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, nil, err
+	}
+	psd, err := gopsd.ParseFromBuffer(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	combined := render.NewCompositeM()
+	for _, layer := range psd.Layers {
+		img, err := layer.GetImage()
+		if err != nil {
+			return nil, nil, err
+		}
+		rgba, ok := img.(*image.RGBA)
+		if !ok {
+			return nil, nil, errors.New("psd layer " + layer.Name + " is not RGBA")
+		}
+		combined.Append(render.NewSprite(float64(layer.Rectangle.X), float64(layer.Rectangle.Y), rgba))
+	}
+	// Make sure this is here in case there is no layer that encompasses the whole thing
+	combined.Append(render.NewEmptySprite(0, 0, int(psd.Width), int(psd.Height)))
+	flat := combined
+	var over *render.CompositeM
+	if overlay {
+		flat = combined.Slice(combined.Len()-2, combined.Len())
+		over = combined.Slice(0, combined.Len()-2)
+	}
+	sh, err := render.MakeSheet(flat.ToSprite().GetRGBA(), w, h, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sh.ToSprites(), over, nil
+}
+
+// overlay combines an overlay with each of the constructor's animations
+func (ec *Constructor) overlay(over *render.CompositeM) {
+	flipped := over.Copy().Modify(mod.FlipX).(*render.CompositeM)
+	for k, md := range ec.AnimationMap {
+		cmp := over.Copy().(*render.CompositeM)
+		if strings.HasSuffix(k, "LT") {
+			cmp = flipped.Copy().(*render.CompositeM)
+		}
+		cmp.Append(md)
+		ec.AnimationMap[k] = cmp.ToSprite()
+	}
+}
+
+func rgba(c [4]uint8) color.RGBA {
+	return color.RGBA{c[0], c[1], c[2], c[3]}
+}
@@ -0,0 +1,117 @@
+package enemies
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/weekly87/internal/characters/doodads"
+)
+
+// A Drop is a chance for an enemy to leave loot behind as it dies
+type Drop struct {
+	Loot   doodads.LootKind
+	Chance float64
+	// Min and Max bound how much the loot is worth
+	Min, Max int64
+}
+
+// DropDef is how a drop is written in the definitions file
+type DropDef struct {
+	Loot   string   `json:"loot"`
+	Chance float64  `json:"chance"`
+	Value  [2]int64 `json:"value"`
+}
+
+// DroppedLoot is sent along with "LootDropped" when an enemy dies
+type DroppedLoot struct {
+	Kind  doodads.LootKind
+	Value int64
+	Pos   floatgeom.Point2
+}
+
+var (
+	// Bigger variants drop more valuable loot
+	sizeLoot = [lastSize]float64{
+		baseSize:  1,
+		largeSize: 1.5,
+		smallSize: .75,
+		giantSize: 2,
+	}
+	// Tougher variants drop loot more often, and more valuable loot
+	colorLoot = [lastColor]float64{
+		baseColor:   1,
+		blueColor:   1.25,
+		redColor:    1.25,
+		blackColor:  3,
+		purpleColor: 1.5,
+	}
+)
+
+// dropSpread is how far apart loot from one enemy lands
+const dropSpread = 12.0
+
+func (def Definition) drops() ([]Drop, error) {
+	drops := make([]Drop, 0, len(def.Drops))
+	for _, dd := range def.Drops {
+		kind, ok := doodads.LootNamed(dd.Loot)
+		if !ok {
+			return nil, fmt.Errorf("unknown %q", dd.Loot)
+		}
+		if dd.Chance <= 0 || dd.Chance > 1 {
+			return nil, fmt.Errorf("%s chance must be above 0 and at most 1", dd.Loot)
+		}
+		min, max := dd.Value[0], dd.Value[1]
+		if min < 1 {
+			min = 1
+		}
+		if max < min {
+			max = min
+		}
+		drops = append(drops, Drop{Loot: kind, Chance: dd.Chance, Min: min, Max: max})
+	}
+	return drops, nil
+}
+
+// scaleDrops makes the drops of a variant richer or poorer
+func (ec *Constructor) scaleDrops(size, col int) {
+	scaled := make([]Drop, len(ec.Drops))
+	for i, d := range ec.Drops {
+		worth := sizeLoot[size] * colorLoot[col]
+		d.Chance = math.Min(1, d.Chance*colorLoot[col])
+		d.Min = int64(math.Max(1, math.Round(float64(d.Min)*worth)))
+		d.Max = int64(math.Max(float64(d.Min), math.Round(float64(d.Max)*worth)))
+		scaled[i] = d
+	}
+	ec.Drops = scaled
+}
+
+// dropLoot rolls the enemy's drops as it dies
+func (be *BasicEnemy) dropLoot() {
+	if be.cons == nil || len(be.cons.Drops) == 0 {
+		return
+	}
+	dropped := []DroppedLoot{}
+	for _, d := range be.cons.Drops {
+		if rand.Float64() >= d.Chance {
+			continue
+		}
+		value := d.Min
+		if d.Max > d.Min {
+			value += rand.Int63n(d.Max - d.Min + 1)
+		}
+		dropped = append(dropped, DroppedLoot{
+			Kind:  d.Loot,
+			Value: value,
+			Pos: floatgeom.Point2{
+				be.X() + be.W/2 + float64(len(dropped))*dropSpread,
+				be.Y() + be.H/2,
+			},
+		})
+	}
+	if len(dropped) != 0 {
+		event.Trigger("LootDropped", dropped)
+	}
+}
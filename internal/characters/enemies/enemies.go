@@ -3,11 +3,16 @@ package enemies
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/oakmound/weekly87/internal/abilities"
 	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/recolor"
 	"github.com/oakmound/weekly87/internal/restrictor"
+	"github.com/oakmound/weekly87/internal/timescale"
 	"github.com/oakmound/weekly87/internal/vfx"
 
 	"github.com/oakmound/oak"
@@ -30,6 +35,9 @@ var requiredAnimations = []string{
 }
 
 type Constructor struct {
+	// Name of the kind of enemy, Variant of its size and color
+	Name       string
+	Variant    string
 	Position   floatgeom.Point2
 	Dimensions floatgeom.Point2
 
@@ -45,11 +53,25 @@ type Constructor struct {
 	AnimationMap map[string]render.Modifiable
 	Bindings     map[string]func(*BasicEnemy, interface{}) int
 	Health       int
+	// Behavior drives how the enemy moves, if set
+	Behavior *Behavior
+	// Affixes are those the enemy can roll, any if empty
+	Affixes []Affix
+	// Drops are what the enemy can leave behind as it dies
+	Drops []Drop
+	// Pursuit is how fast the enemy chases the party as it runs back, 0 if it turns around instead
+	Pursuit float64
+
+	// tints holds the animations tinted for each status effect, built when first needed
+	tintLock sync.Mutex
+	tints    map[status.Kind]map[string]render.Modifiable
 }
 
 // Copy the data values to new instances of an enemy constructor
 func (ec *Constructor) Copy() *Constructor {
 	c2 := &Constructor{
+		Name:        ec.Name,
+		Variant:     ec.Variant,
 		Position:    ec.Position,
 		Dimensions:  ec.Dimensions,
 		SpaceOffset: ec.SpaceOffset,
@@ -57,6 +79,10 @@ func (ec *Constructor) Copy() *Constructor {
 		// Todo: Assuming right now that the bindings map never gets modified (by a variant)
 		Bindings:     ec.Bindings,
 		Health:       ec.Health,
+		Behavior:     ec.Behavior,
+		Affixes:      ec.Affixes,
+		Drops:        ec.Drops,
+		Pursuit:      ec.Pursuit,
 		AnimationMap: make(map[string]render.Modifiable, len(ec.AnimationMap)),
 	}
 	for k, v := range ec.AnimationMap {
@@ -65,12 +91,35 @@ func (ec *Constructor) Copy() *Constructor {
 	return c2
 }
 
-var Constructors [TypeLimit * VariantCount]*Constructor
+// tinted returns the constructor's animations tinted for each status effect
+func (ec *Constructor) tinted() map[status.Kind]map[string]render.Modifiable {
+	ec.tintLock.Lock()
+	defer ec.tintLock.Unlock()
+	if ec.tints != nil {
+		return ec.tints
+	}
+	ec.tints = make(map[status.Kind]map[string]render.Modifiable, status.KindLimit)
+	for k := status.None + 1; k < status.KindLimit; k++ {
+		anims := make(map[string]render.Modifiable, len(ec.AnimationMap))
+		for animKey, anim := range ec.AnimationMap {
+			tinted := anim.Copy()
+			tinted.Filter(recolor.WithStrategy(recolor.ColorMix(status.Rules[k].Tint)))
+			anims[animKey] = tinted
+		}
+		ec.tints[k] = anims
+	}
+	return ec.tints
+}
 
-func setConstructor(eType, size, color int, cons *Constructor) {
-	Constructors[(eType*VariantCount)+(size*lastColor)+color] = cons
+// tintKey is the name of an animation tinted for a status effect
+func tintKey(animKey string, k status.Kind) string {
+	if k == status.None {
+		return animKey
+	}
+	return animKey + "-" + k.String()
 }
 
+// GetConstructor returns the constructor of a variant of a kind of enemy
 func GetConstructor(eType, size, color int) *Constructor {
 	return Constructors[(eType*VariantCount)+(size*lastColor)+color]
 }
@@ -84,6 +133,33 @@ type BasicEnemy struct {
 	pushBack      physics.Vector
 	baseSpeed     physics.Vector
 	Health        int
+	// wounds are damage that hasn't added up to a point of health yet
+	wounds   float64
+	statuses status.Set
+	tint     status.Kind
+	// tinted marks the status tints whose animations the switch has been given
+	tinted [status.KindLimit]bool
+	mind   *mind
+
+	dying     bool
+	name      string
+	cons      *Constructor
+	maxHealth int
+	// affixes make the enemy an elite, pips mark them
+	affixes      []Affix
+	pips         []*render.Sprite
+	shields      int
+	guardedUntil time.Time
+	raging       bool
+	nextRegen    time.Time
+	nextTeleport time.Time
+	// bar shows health once the enemy is hurt
+	bar       *render.Sprite
+	barHealth int
+	// pack is the group the enemy spawned in, if any
+	pack *Pack
+	// pursuing enemies chase the party as it runs back
+	pursuing bool
 }
 
 func (be *BasicEnemy) Init() event.CID {
@@ -93,14 +169,39 @@ func (be *BasicEnemy) Init() event.CID {
 func (be *BasicEnemy) Activate() {
 	be.Active = true
 	restrictor.Add(be)
+	be.drawPips()
+	if be.bar != nil {
+		render.Draw(be.bar, layer.Play, 2)
+	}
 }
 
 func (be *BasicEnemy) Destroy() {
 	be.Active = false
+	be.leavePack()
+	for _, pip := range be.pips {
+		pip.Undraw()
+	}
+	if be.bar != nil {
+		be.bar.Undraw()
+	}
 	be.Interactive.Destroy()
 }
 
+// onScreen reports whether the enemy can be seen
+func (be *BasicEnemy) onScreen() bool {
+	return be.X() <= float64(oak.ScreenWidth+oak.ViewPos.X) &&
+		be.X()+be.W >= float64(oak.ViewPos.X)
+}
+
 func (be *BasicEnemy) DeathEffect(secid, idx int64) {
+	// Effects keep hitting while the enemy is knocked away, but it only dies once
+	if be.dying {
+		return
+	}
+	be.dying = true
+	be.leavePack()
+	be.dropLoot()
+	be.split(secid)
 	be.RSpace.Label = 0
 	be.PushBack(physics.NewVector(60, 0))
 	abilities.Produce(
@@ -118,6 +219,7 @@ func (be *BasicEnemy) DeathEffect(secid, idx int64) {
 			return 0
 		}
 		event.Trigger("EnemyDeath", []int64{secid, idx})
+		event.Trigger("EnemyKilled", be.name)
 
 		w, h := be.R.GetDims()
 
@@ -144,6 +246,103 @@ func (be *BasicEnemy) DeathEffect(secid, idx int64) {
 
 }
 
+// Hurt the enemy, scaled by its status effects. Returns whether it died.
+func (be *BasicEnemy) hurt(dmg float64, secid, idx int64) bool {
+	// Whatever is still hitting an enemy on its way out can't kill it again
+	if be.dying || !be.Active {
+		return false
+	}
+	be.wounds += dmg * be.statuses.DamageScale()
+	whole := int(be.wounds)
+	be.wounds -= float64(whole)
+	be.Health -= whole
+	be.showDamage(whole)
+	if be.Health < 1 {
+		be.dying = true
+		be.leavePack()
+		be.dropLoot()
+		be.split(secid)
+		event.Trigger("EnemyDeath", []int64{secid, idx})
+		event.Trigger("EnemyKilled", be.name)
+		be.Destroy()
+		return true
+	}
+	if dmg > 0 {
+		be.wounded()
+	}
+	return false
+}
+
+// attacked applies an attack to the enemy, along with the reactions it sets off
+func (be *BasicEnemy) attacked(atk status.Attack, secid, idx int64) {
+	if !be.Active || be.guarded() {
+		return
+	}
+	now := timescale.EnemyNow()
+	reactions := be.statuses.React(atk, now)
+	if atk.Pushback != 0 {
+		be.PushBack(physics.NewVector(atk.Pushback, 0))
+	}
+	if atk.Damage != 0 && be.hurt(atk.Damage, secid, idx) {
+		return
+	}
+	for _, e := range atk.Effects {
+		be.statuses.Apply(e, now)
+		be.showEffect(e.Kind)
+	}
+	for _, r := range reactions {
+		be.react(r)
+		be.floatAbove(r.Name, r.Color)
+		// Reactions have no element of their own, so they can't set off more reactions
+		be.attacked(r.Attack, secid, idx)
+		if be.Health < 1 {
+			return
+		}
+	}
+}
+
+// react shows a reaction happening and splashes the enemies around it
+func (be *BasicEnemy) react(r status.Reaction) {
+	w, h := be.GetDims()
+	center := floatgeom.Point2{be.X() + float64(w)/2, be.Y() + float64(h)/2}
+	abilities.Produce(
+		abilities.StartAt(center),
+		abilities.WithParticles(vfx.Burst(r.Color)),
+		abilities.Duration(time.Millisecond*40),
+	)
+	if r.Radius <= 0 {
+		return
+	}
+	area := collision.NewUnassignedSpace(center.X()-r.Radius, center.Y()-r.Radius, r.Radius*2, r.Radius*2)
+	for _, sp := range collision.Hits(area) {
+		if sp.Label != labels.Enemy || sp.CID == be.CID {
+			continue
+		}
+		sp.CID.Trigger("Attacked", r.Splash)
+	}
+}
+
+// setTint changes the status the enemy is tinted for. Most enemies never see most
+// statuses, so each tint's animations are copied from the constructor when first needed.
+func (be *BasicEnemy) setTint(k status.Kind) {
+	if !be.tinted[k] {
+		for animKey, anim := range be.cons.tinted()[k] {
+			key := tintKey(animKey, k)
+			be.swtch.Add(key, anim.Copy())
+			if be.cons.SpaceOffset != (physics.Vector{}) {
+				be.swtch.SetOffsets(key, be.cons.SpaceOffset)
+			}
+		}
+		be.tinted[k] = true
+	}
+	be.tint = k
+}
+
+// Statuses are the effects currently on the enemy
+func (be *BasicEnemy) Statuses() *status.Set {
+	return &be.statuses
+}
+
 func (be *BasicEnemy) CheckedBind(bnd func(*BasicEnemy, interface{}) int, ev string) {
 	be.Bind(func(id int, data interface{}) int {
 		be, ok := event.GetEntity(id).(*BasicEnemy)
@@ -176,10 +375,11 @@ func (ec *Constructor) NewEnemy(secid, idx int64) (*BasicEnemy, error) {
 	be.swtch = render.NewSwitch("standLT", newMp)
 	if ec.SpaceOffset != (physics.Vector{}) {
 
-		for animKey := range ec.AnimationMap {
+		for animKey := range newMp {
 			be.swtch.SetOffsets(animKey, ec.SpaceOffset)
 		}
 	}
+	be.tinted[status.None] = true
 	be.Interactive = entities.NewInteractive(
 		ec.Position.X(),
 		ec.Position.Y(),
@@ -192,11 +392,18 @@ func (ec *Constructor) NewEnemy(secid, idx int64) (*BasicEnemy, error) {
 	)
 	// be.swtch.SetOffsets("walkLT", )
 	be.Health = ec.Health
+	be.maxHealth = ec.Health
+	be.barHealth = ec.Health
+	be.name = ec.Name
+	be.cons = ec
 	be.Speed = physics.NewVector(ec.Speed.X(), ec.Speed.Y())
 	be.baseSpeed = be.Speed.Copy()
 	be.facing = "LT"
 	be.RSpace.Label = labels.Enemy
 	be.CheckedBind(func(be *BasicEnemy, _ interface{}) int {
+		if be.startPursuit() {
+			return 0
+		}
 		be.facing = "RT"
 		be.Speed = be.Speed.Scale(-1)
 		return 0
@@ -210,10 +417,25 @@ func (ec *Constructor) NewEnemy(secid, idx int64) (*BasicEnemy, error) {
 		if be.facing == "RT" {
 			push.Scale(-1)
 		}
-		be.Delta = be.Speed.Copy().Add(push)
-		be.pushBack.Scale(0.95)
-		if be.X() <= float64(oak.ScreenWidth+oak.ViewPos.X) &&
-			be.X()+be.W >= float64(oak.ViewPos.X) {
+		now := timescale.EnemyNow()
+		if dmg := be.statuses.Update(now); dmg > 0 && be.hurt(dmg, secid, idx) {
+			return 0
+		}
+		be.affixTick(now)
+		be.showHealth()
+		be.think(now)
+		be.keepFormation()
+		be.pursue()
+
+		// Time may be passing slower or not at all where we stand
+		ts := timescale.EnemyAt(floatgeom.Point2{be.X(), be.Y()})
+		be.Delta = be.Speed.Copy().Scale(be.statuses.SpeedScale()).Add(push).Scale(ts)
+		be.pushBack.Scale(1 - .05*ts)
+		if be.onScreen() {
+			if !be.beenDisplayed {
+				be.beenDisplayed = true
+				be.sighted(secid)
+			}
 			//be.RSpace.Label = labels.Enemy
 			be.ShiftPos(be.Delta.X(), be.Delta.Y())
 			// Default behavior is to flip when hitting the ceiling
@@ -224,10 +446,11 @@ func (ec *Constructor) NewEnemy(secid, idx int64) (*BasicEnemy, error) {
 				be.ShiftPos(0, be.Speed.Y())
 			}
 		}
+		be.setTint(be.statuses.Tint())
 		if be.Delta.X() != 0 || be.Delta.Y() != 0 {
-			be.swtch.Set("walk" + be.facing)
+			be.swtch.Set(tintKey("walk"+be.facing, be.tint))
 		} else {
-			be.swtch.Set("stand" + be.facing)
+			be.swtch.Set(tintKey("stand"+be.facing, be.tint))
 		}
 		<-be.RSpace.CallOnHits()
 		return 0
@@ -243,40 +466,19 @@ func (ec *Constructor) NewEnemy(secid, idx int64) (*BasicEnemy, error) {
 
 		fmt.Println("Consider moving this effect to trigger vie the attacked event", be)
 
+		if be.guarded() {
+			return
+		}
 		be.DeathEffect(secid, idx)
 	})
 	be.CheckedBind(func(be *BasicEnemy, data interface{}) int {
 
-		effectMap, ok := data.(map[string]float64)
+		atk, ok := data.(status.Attack)
 		if !ok {
 			dlog.Warn("Data sent on attack was not in the right format")
 			return 0
 		}
-
-		for k, v := range effectMap {
-			switch k {
-			case "pushback":
-				be.PushBack(physics.NewVector(v, 0))
-			case "damage":
-				be.Health -= int(v)
-				if be.Health < 1 {
-					event.Trigger("EnemyDeath", []int64{secid, idx})
-					be.Destroy()
-				}
-			case "frost":
-				endDebuff := time.Now().Add(time.Second * 3)
-				be.CheckedBind(func(be *BasicEnemy, data interface{}) int {
-					if !time.Now().After(endDebuff) {
-						return 0
-					}
-					be.Speed = be.Speed.Scale(v)
-					return event.UnbindSingle
-				}, "EnterFrame")
-				be.Speed = be.Speed.Scale(1 / v)
-				dlog.Verb("BE speed is now", be.Speed)
-			}
-		}
-
+		be.attacked(atk, secid, idx)
 		return 0
 	}, "Attacked")
 	// be.RSpace.Add(labels.PlayerAttack, func(s, _ *collision.Space) {
@@ -289,6 +491,17 @@ func (ec *Constructor) NewEnemy(secid, idx int64) (*BasicEnemy, error) {
 	// 	event.Trigger("EnemyDeath", []int64{secid, idx})
 	// 	be.Destroy()
 	// })
+	if ec.Behavior != nil {
+		be.mind = newMind(ec.Behavior)
+		for _, ev := range ec.Behavior.events() {
+			ev := ev
+			be.CheckedBind(func(be *BasicEnemy, _ interface{}) int {
+				be.mind.hear(ev)
+				return 0
+			}, ev)
+		}
+		be.enter(ec.Behavior.Start, timescale.EnemyNow())
+	}
 	for ev, b := range ec.Bindings {
 		be.CheckedBind(b, ev)
 	}
@@ -2,61 +2,54 @@ package enemies
 
 import (
 	"math/rand"
-	"path/filepath"
-
-	"github.com/oakmound/oak/render/mod"
-
-	"github.com/oakmound/oak/dlog"
+	"time"
 
 	"github.com/oakmound/oak/physics"
-	"github.com/oakmound/oak/render"
-
-	"github.com/oakmound/oak/alg/floatgeom"
 )
 
-func initHare() {
-	sheet, err := render.LoadSprites(filepath.Join("assets", "images"),
-		filepath.Join("32x32", "Hare.png"), 32, 32, 0)
-	dlog.ErrorCheck(err)
-	anims := map[string]render.Modifiable{}
-	anims["standRT"] = sheet[0][0].Copy()
-	anims["standLT"] = sheet[0][0].Copy().Modify(mod.FlipX)
-	anims["walkRT"] = render.NewSequence(4, sheet[0][0].Copy(), sheet[1][0].Copy())
-	anims["walkLT"] = anims["walkRT"].Copy().Modify(mod.FlipX)
-
-	baseConstructor := Constructor{
-		Dimensions:   floatgeom.Point2{32, 32},
-		AnimationMap: anims,
-		Speed:        floatgeom.Point2{3, 2},
-		Bindings: map[string]func(*BasicEnemy, interface{}) int{
-			"EnterFrame": func(b *BasicEnemy, frame interface{}) int {
-				f := frame.(int)
-				// Simulate hops
-				if f%52 == 0 {
-					b.Speed = physics.NewVector(0, 0)
-				} else if f%70 == 0 {
-					b.Speed = physics.NewVector(
-						-(rand.Float64()*b.baseSpeed.X()+1)*3,
-						rand.Float64()*b.baseSpeed.Y()*float64(rand.Intn(2)*2-1),
-					)
-					if b.facing == "RT" {
-						b.Speed.Scale(-1)
-					}
-				}
-				return 0
+// hareFright is how close the party can get before hares bolt. Most hares die to
+// a single hit, so they have to run before they are hurt rather than after.
+const hareFright = 140.0
+
+// Hares hop about, and hop away from the party when it comes close or hurts them
+var hareBehavior = &Behavior{
+	Start: Idle,
+	States: map[State]StateDef{
+		Idle: {
+			Enter: Stand,
+			Transitions: []Transition{
+				{To: Flee, On: "Attacked"},
+				// Startled hares freeze for a moment before they bolt
+				{To: Flee, When: All(After(150*time.Millisecond), Near(hareFright))},
+				{To: Patrol, When: After(400 * time.Millisecond)},
 			},
 		},
-		Health: 1,
-	}
+		Patrol: {
+			Enter: hop,
+			Transitions: []Transition{
+				{To: Flee, On: "Attacked"},
+				{To: Flee, When: Near(hareFright)},
+				{To: Idle, When: After(800 * time.Millisecond)},
+			},
+		},
+		Flee: {
+			Enter: Away(1.5),
+			Transitions: []Transition{
+				{To: Idle, When: After(500 * time.Millisecond)},
+			},
+		},
+		Stunned: StunnedState(Idle),
+	},
+	Any: Stunnable,
+}
 
-	// Todo: if this gets more complicated, we can have initHare return the baseConstructor
-	// and do this in one place for all enemies
-	for size := 0; size < lastSize; size++ {
-		for color := 0; color < lastColor; color++ {
-			cons := baseConstructor.Copy()
-			sizeVariants[size](cons)
-			colorVariants[color](cons)
-			setConstructor(int(Hare), size, color, cons)
-		}
+// hop in a random direction, mostly forward
+func hop(b *BasicEnemy) {
+	b.Speed = physics.NewVector(
+		-(rand.Float64()*b.baseSpeed.X()+1)*3,
+		rand.Float64()*b.baseSpeed.Y()*float64(rand.Intn(2)*2-1),
+	)
+	if b.facing == "RT" {
+		b.Speed.Scale(-1)
 	}
 }
@@ -0,0 +1,81 @@
+package enemies
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/characters/status"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/settingsmanagement/settings"
+	"github.com/oakmound/weekly87/internal/vfx"
+)
+
+const (
+	barH = 3
+	// barY sits the health bar between the enemy and its affix pips
+	barY = -4
+)
+
+var (
+	barFill  = color.RGBA{200, 30, 30, 255}
+	barEmpty = color.RGBA{30, 10, 10, 200}
+	hitColor = color.RGBA{255, 255, 255, 255}
+)
+
+// showHealth keeps the enemy's health bar in step with its health. The bar is only
+// shown once the enemy has been hurt.
+func (be *BasicEnemy) showHealth() {
+	if be.Health == be.barHealth || !settings.Active.ShowCombatInfo {
+		return
+	}
+	be.barHealth = be.Health
+	if be.bar == nil {
+		if be.Health >= be.maxHealth {
+			return
+		}
+		w := int(be.W)
+		if w < 1 {
+			w = 1
+		}
+		be.bar = render.NewColorBox(w, barH, barEmpty)
+		be.bar.Vector = be.bar.Attach(be.Vector, 0, barY)
+		if be.Active {
+			render.Draw(be.bar, layer.Play, 2)
+		}
+	}
+	rgba := be.bar.GetRGBA()
+	w := rgba.Bounds().Max.X
+	filled := w * be.Health / be.maxHealth
+	for x := 0; x < w; x++ {
+		c := barEmpty
+		if x < filled {
+			c = barFill
+		}
+		for y := 0; y < barH; y++ {
+			rgba.Set(x, y, c)
+		}
+	}
+}
+
+// floatAbove shows text rising from the top of the enemy
+func (be *BasicEnemy) floatAbove(s string, c color.RGBA) {
+	if !settings.Active.ShowCombatInfo || !be.onScreen() {
+		return
+	}
+	vfx.Float(s, c, be.X()+be.W/2, be.Y()+barY*3)
+}
+
+// showDamage floats the damage a hit dealt
+func (be *BasicEnemy) showDamage(dmg int) {
+	if dmg > 0 {
+		be.floatAbove(strconv.Itoa(dmg), hitColor)
+	}
+}
+
+// showEffect floats the name of an effect put on the enemy
+func (be *BasicEnemy) showEffect(k status.Kind) {
+	c := status.Rules[k].Tint
+	c.A = 255
+	be.floatAbove(k.String(), c)
+}
@@ -1,44 +1,33 @@
 package enemies
 
 import (
-	"math/rand"
-	"path/filepath"
-
-	"github.com/oakmound/oak/render/mod"
-
-	"github.com/oakmound/oak/dlog"
-
-	"github.com/oakmound/oak/render"
-
-	"github.com/oakmound/oak/alg/floatgeom"
+	"image/color"
+	"time"
 )
 
-func initMantis() {
-	sheet, err := render.LoadSprites(filepath.Join("assets", "images"),
-		filepath.Join("32x32", "mantis.png"), 32, 32, 0)
-	dlog.ErrorCheck(err)
-	anims := map[string]render.Modifiable{}
-	anims["standRT"] = sheet[0][0].Copy()
-	anims["standLT"] = sheet[0][0].Copy().Modify(mod.FlipX)
-	anims["walkRT"] = render.NewSequence(4, sheet[0][0].Copy(), sheet[1][0].Copy())
-	anims["walkLT"] = anims["walkRT"].Copy().Modify(mod.FlipX)
-
-	baseConstructor := Constructor{
-		Dimensions:   floatgeom.Point2{32, 32},
-		AnimationMap: anims,
-		Speed: floatgeom.Point2{
-			-1 * ((rand.Float64() * 4) + 1),
-			-1 * ((rand.Float64() * 4) + 1),
+// Mantises walk until the party is close, then rear up and charge at it
+var mantisBehavior = &Behavior{
+	Start: Patrol,
+	States: map[State]StateDef{
+		Patrol: {
+			Enter: Walk,
+			Transitions: []Transition{
+				{To: Telegraph, When: All(After(time.Second), Near(250))},
+			},
 		},
-		Health: 1,
-	}
-
-	for size := 0; size < lastSize; size++ {
-		for color := 0; color < lastColor; color++ {
-			cons := baseConstructor.Copy()
-			sizeVariants[size](cons)
-			colorVariants[color](cons)
-			setConstructor(int(Mantis), size, color, cons)
-		}
-	}
+		Telegraph: {
+			Enter: Both(Stand, Flash(color.RGBA{255, 255, 120, 200})),
+			Transitions: []Transition{
+				{To: Charge, When: After(400 * time.Millisecond)},
+			},
+		},
+		Charge: {
+			Enter: Toward(2),
+			Transitions: []Transition{
+				{To: Patrol, When: After(800 * time.Millisecond)},
+			},
+		},
+		Stunned: StunnedState(Patrol),
+	},
+	Any: Stunnable,
 }
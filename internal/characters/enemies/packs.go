@@ -0,0 +1,290 @@
+package enemies
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/physics"
+)
+
+// A Formation is how the members of a pack stand around their leader
+type Formation int
+
+const (
+	// Swarm scatters members loosely around the leader
+	Swarm Formation = iota
+	// Line stands members in a column across the hallway, to charge together
+	Line
+	// Cluster rings members around the leader
+	Cluster
+)
+
+const (
+	// formationPull is how hard members steer back to their place in the formation
+	formationPull = .05
+	// surroundRange is how close the party has to be for a pack to surround it
+	surroundRange = 300.0
+	// surroundRadius is how far from the party surrounding members stand
+	surroundRadius = 70.0
+	surroundSpeed  = 1.5
+	scatterSpeed   = 2.0
+)
+
+// A PackPlan is a group of enemies that a section can spawn together
+type PackPlan struct {
+	// Type is the name of the kind of enemy in the pack
+	Type      string
+	Min, Max  int
+	Formation Formation
+	// Spacing is how far apart members stand
+	Spacing float64
+	// Surround has members break formation to circle the party when it comes near
+	Surround bool
+}
+
+// Size rolls how many enemies are in a pack
+func (pp PackPlan) Size(rng *rand.Rand) int {
+	if pp.Max <= pp.Min {
+		return pp.Min
+	}
+	return pp.Min + rng.Intn(pp.Max-pp.Min+1)
+}
+
+// offsets are where each member of a pack stands relative to the leader
+func (pp PackPlan) offsets(n int, rng *rand.Rand) []floatgeom.Point2 {
+	offs := make([]floatgeom.Point2, n)
+	for i := 1; i < n; i++ {
+		switch pp.Formation {
+		case Line:
+			// Alternate above and below the leader
+			step := float64((i + 1) / 2)
+			if i%2 == 0 {
+				step = -step
+			}
+			offs[i] = floatgeom.Point2{0, step * pp.Spacing}
+		case Cluster:
+			angle := 2 * math.Pi * float64(i-1) / float64(n-1)
+			offs[i] = floatgeom.Point2{math.Cos(angle) * pp.Spacing, math.Sin(angle) * pp.Spacing}
+		default:
+			reach := pp.Spacing * math.Sqrt(float64(n))
+			offs[i] = floatgeom.Point2{(rng.Float64()*2 - 1) * reach, (rng.Float64()*2 - 1) * reach}
+		}
+	}
+	return offs
+}
+
+// A Pack is a group of enemies that moves with its leader, and scatters when it falls.
+// Each member runs in its own bindings, so members only ever change themselves and
+// everything they share goes through the pack, under its lock.
+type Pack struct {
+	sync.Mutex
+	leader   *BasicEnemy
+	members  []*BasicEnemy
+	offsets  []floatgeom.Point2
+	scatter  []floatgeom.Point2
+	surround bool
+	// left marks members that died or went off on their own, scattered those that
+	// have broken formation since the leader died
+	left      []bool
+	scattered []bool
+	// order is the last state the leader entered. orders counts them, and heard is
+	// how many of them each member has followed.
+	order  State
+	orders int
+	heard  []int
+	// where the leader is, how it is moving and what it is doing, as of its last frame
+	leaderPos   floatgeom.Point2
+	leaderSpeed physics.Vector
+	leaderState State
+}
+
+// FormPack gathers enemies into a pack around a position, led by the first of them.
+// Everything random about the pack is rolled from rng, so it is the same for the same seed.
+func FormPack(members []*BasicEnemy, pp PackPlan, at floatgeom.Point2, rng *rand.Rand) *Pack {
+	if len(members) == 0 {
+		return nil
+	}
+	pk := &Pack{
+		leader:    members[0],
+		members:   members,
+		offsets:   pp.offsets(len(members), rng),
+		scatter:   make([]floatgeom.Point2, len(members)),
+		surround:  pp.Surround,
+		left:      make([]bool, len(members)),
+		scattered: make([]bool, len(members)),
+		heard:     make([]int, len(members)),
+	}
+	for i, m := range members {
+		angle := rng.Float64() * 2 * math.Pi
+		pk.scatter[i] = floatgeom.Point2{math.Cos(angle), math.Sin(angle)}
+		m.pack = pk
+		m.SetPos(at.X()+pk.offsets[i].X(), at.Y()+pk.offsets[i].Y())
+	}
+	leader := members[0]
+	pk.leaderPos = floatgeom.Point2{leader.X(), leader.Y()}
+	pk.leaderSpeed = leader.Speed.Copy()
+	pk.leaderState = leader.State()
+	return pk
+}
+
+// follower is the index of an enemy that follows the pack's leader, or -1 if it
+// doesn't. The pack must be locked.
+func (pk *Pack) follower(be *BasicEnemy) int {
+	if pk.leader == nil || pk.leader == be {
+		return -1
+	}
+	idx := pk.index(be)
+	if idx < 0 || pk.left[idx] {
+		return -1
+	}
+	return idx
+}
+
+// following is whether the enemy takes its lead from the leader of a pack
+func (be *BasicEnemy) following() bool {
+	pk := be.pack
+	if pk == nil {
+		return false
+	}
+	pk.Lock()
+	defer pk.Unlock()
+	return pk.follower(be) >= 0
+}
+
+// lead has the rest of a leader's pack follow it into a state, once they hear of it
+func (be *BasicEnemy) lead(s State) {
+	pk := be.pack
+	if pk == nil {
+		return
+	}
+	pk.Lock()
+	if pk.leader == be {
+		pk.order = s
+		pk.orders++
+		pk.leaderState = s
+	}
+	pk.Unlock()
+}
+
+// ordered returns the state the leader last entered, if the enemy follows it and
+// hasn't followed it there yet
+func (be *BasicEnemy) ordered() (State, bool) {
+	pk := be.pack
+	if pk == nil {
+		return 0, false
+	}
+	pk.Lock()
+	defer pk.Unlock()
+	idx := pk.follower(be)
+	if idx < 0 || pk.heard[idx] == pk.orders {
+		return 0, false
+	}
+	pk.heard[idx] = pk.orders
+	return pk.order, true
+}
+
+// keepFormation steers a follower back to its place in the pack, or around the
+// party if the pack surrounds it. Leaders tell the pack where they are instead.
+func (be *BasicEnemy) keepFormation() {
+	pk := be.pack
+	if pk == nil {
+		return
+	}
+	pk.Lock()
+	if pk.leader == be {
+		pk.leaderPos = floatgeom.Point2{be.X(), be.Y()}
+		pk.leaderSpeed = be.Speed.Copy()
+		pk.leaderState = be.State()
+		pk.Unlock()
+		return
+	}
+	if be.State() == Stunned {
+		pk.Unlock()
+		return
+	}
+	idx := pk.index(be)
+	if idx < 0 || pk.left[idx] || pk.scattered[idx] {
+		pk.Unlock()
+		return
+	}
+	if pk.leader == nil {
+		// The leader fell, so the pack scatters
+		pk.scattered[idx] = true
+		dir := pk.scatter[idx]
+		pk.Unlock()
+		speed := math.Max(math.Hypot(be.baseSpeed.X(), be.baseSpeed.Y()), 1) * scatterSpeed
+		be.Speed = physics.NewVector(dir.X()*speed, dir.Y()*speed)
+		return
+	}
+	leaderPos, leaderSpeed, leaderState := pk.leaderPos, pk.leaderSpeed.Copy(), pk.leaderState
+	slot := pk.offsets[idx]
+	n := len(pk.members)
+	pk.Unlock()
+
+	speed := math.Hypot(be.baseSpeed.X(), be.baseSpeed.Y())
+	if pk.surround {
+		if pc, ok := be.nearestPC(surroundRange); ok {
+			angle := 2 * math.Pi * float64(idx) / float64(n)
+			be.steerTo(floatgeom.Point2{
+				pc.X() + math.Cos(angle)*surroundRadius,
+				pc.Y() + math.Sin(angle)*surroundRadius,
+			}, speed*surroundSpeed)
+			return
+		}
+	}
+	switch leaderState {
+	case Idle, Patrol:
+	default:
+		// Members charge and flee on their own, with the leader
+		return
+	}
+	// Places off the edge of the hallway are held as close as the hallway allows
+	fieldTop, fieldBottom := float64(oak.ScreenHeight)*1/3, float64(oak.ScreenHeight)-64
+	targetY := math.Max(fieldTop, math.Min(fieldBottom, leaderPos.Y()+slot.Y()))
+	be.Speed = leaderSpeed.Add(physics.NewVector(
+		(leaderPos.X()+slot.X()-be.X())*formationPull,
+		(targetY-be.Y())*formationPull,
+	))
+}
+
+// steerTo heads the enemy for a point, slowing as it arrives
+func (be *BasicEnemy) steerTo(pt floatgeom.Point2, speed float64) {
+	dx, dy := pt.X()-be.X(), pt.Y()-be.Y()
+	d := math.Hypot(dx, dy)
+	if d < 1 {
+		be.Speed = physics.NewVector(0, 0)
+		return
+	}
+	speed = math.Min(speed, d)
+	be.Speed = physics.NewVector(dx/d*speed, dy/d*speed)
+}
+
+// index of a member in the pack, -1 if it isn't one. The pack must be locked.
+func (pk *Pack) index(be *BasicEnemy) int {
+	for i, m := range pk.members {
+		if m == be {
+			return i
+		}
+	}
+	return -1
+}
+
+// leavePack takes an enemy out of its pack as it dies, is destroyed or goes off on its own.
+// If it led the pack, the rest scatter as they notice.
+func (be *BasicEnemy) leavePack() {
+	pk := be.pack
+	if pk == nil {
+		return
+	}
+	pk.Lock()
+	defer pk.Unlock()
+	if idx := pk.index(be); idx >= 0 {
+		pk.left[idx] = true
+	}
+	if pk.leader == be {
+		pk.leader = nil
+	}
+}
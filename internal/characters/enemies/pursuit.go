@@ -0,0 +1,47 @@
+package enemies
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/physics"
+)
+
+// pursuitSight is how far away a pursuer can still find the party. Pursuers that
+// fall off screen stop moving and are culled, so this only has to cover the screen.
+const pursuitSight = 2000.0
+
+// Pursuing is whether the enemy is chasing the party as it runs back.
+// Pursuers follow the party out of the section they were made in.
+func (be *BasicEnemy) Pursuing() bool {
+	return be.pursuing && be.Active && !be.dying
+}
+
+// startPursuit has the enemy chase the party instead of turning around, if it can
+func (be *BasicEnemy) startPursuit() bool {
+	if be.cons == nil || be.cons.Pursuit <= 0 {
+		return false
+	}
+	be.pursuing = true
+	// Pursuers chase on their own
+	be.leavePack()
+	return true
+}
+
+// pursue steers the enemy after the party, or on the way it ran if it can't be found
+func (be *BasicEnemy) pursue() {
+	if !be.pursuing || be.State() == Stunned {
+		return
+	}
+	speed := be.cons.Pursuit
+	pc, ok := be.nearestPC(pursuitSight)
+	if !ok {
+		be.Speed = physics.NewVector(-speed, 0)
+		return
+	}
+	dx, dy := pc.X()-be.X(), pc.Y()-be.Y()
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return
+	}
+	be.Speed = physics.NewVector(dx/d*speed, dy/d*speed)
+}
@@ -0,0 +1,183 @@
+package enemies
+
+import (
+	"image/color"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/recolor"
+	"github.com/oakmound/weekly87/internal/timescale"
+)
+
+// A Shot is how a projectile flies
+type Shot int
+
+const (
+	// Straight shots fly in a line past where the party was
+	Straight Shot = iota
+	// Arcing shots are lobbed up and land where the party was
+	Arcing
+	// Homing shots turn to follow the party until they run out
+	Homing
+	shotLimit
+)
+
+const (
+	// shotRange is how close the party has to be for an enemy to shoot at it,
+	// and how far straight shots fly
+	shotRange = 500.0
+	// shotSpeed is how far shots fly each frame
+	shotSpeed = 5.0
+	// arcHeight is how far above its ends an arcing shot peaks
+	arcHeight = 120.0
+	// homingLife is how long homing shots last, homingTurn how quickly they turn
+	homingLife = 4 * time.Second
+	homingTurn = .06
+)
+
+var (
+	shotColors = [shotLimit]color.RGBA{
+		Straight: {255, 140, 40, 150},
+		Arcing:   {120, 220, 80, 150},
+		Homing:   {200, 80, 255, 150},
+	}
+	shotSprites [shotLimit]*render.Sprite
+)
+
+// Shoot at the nearest member of the party, if there is one in range
+func Shoot(kind Shot) func(*BasicEnemy) {
+	return func(be *BasicEnemy) {
+		if !be.Active {
+			return
+		}
+		if target, ok := be.nearestPC(shotRange); ok {
+			be.shoot(kind, target)
+		}
+	}
+}
+
+// shoot a projectile at a target
+func (be *BasicEnemy) shoot(kind Shot, target floatgeom.Point2) {
+	w, h := be.GetDims()
+	start := floatgeom.Point2{be.X() + float64(w)/2, be.Y() + float64(h)/2}
+	dx, dy := target.X()-start.X(), target.Y()-start.Y()
+	dist := math.Hypot(dx, dy)
+	if dist == 0 {
+		return
+	}
+	opts := []abilities.Option{
+		abilities.StartAt(start),
+		abilities.WithRenderable(shotSprites[kind].Copy()),
+		abilities.WithLabel(labels.EnemyAttack),
+		abilities.FiredBy(be.Name()),
+	}
+	switch kind {
+	case Straight:
+		end := floatgeom.Point2{start.X() + dx/dist*shotRange, start.Y() + dy/dist*shotRange}
+		opts = append(opts,
+			abilities.LineTo(end),
+			abilities.FrameLength(int(shotRange/shotSpeed)),
+		)
+	case Arcing:
+		peak := floatgeom.Point2{start.X() + dx/2, math.Min(start.Y(), target.Y()) - arcHeight}
+		opts = append(opts,
+			abilities.ArcTo(peak, target),
+			abilities.FrameLength(int((dist+arcHeight)/shotSpeed)+1),
+		)
+	case Homing:
+		opts = append(opts, abilities.Duration(homingLife))
+	}
+	chrs, err := abilities.Produce(opts...)
+	if err != nil {
+		dlog.Error("Failed to shoot", err)
+		return
+	}
+	// Shots aren't the party's, so they are kept out of its sections
+	event.Trigger("EnemyFired", chrs)
+	if kind != Homing {
+		return
+	}
+	for _, chr := range chrs {
+		prd, ok := chr.(*abilities.Product)
+		if !ok {
+			continue
+		}
+		home(prd, floatgeom.Point2{dx / dist * shotSpeed, dy / dist * shotSpeed})
+	}
+}
+
+// home steers a shot toward the nearest member of the party
+func home(prd *abilities.Product, vel floatgeom.Point2) {
+	prd.CID.Bind(func(id int, _ interface{}) int {
+		prd, ok := event.GetEntity(id).(*abilities.Product)
+		if !ok {
+			dlog.Error("Non product sent to homing enter frame")
+			return event.UnbindSingle
+		}
+		pos := floatgeom.Point2{prd.X(), prd.Y()}
+		if target, ok := nearestPC(pos, shotRange); ok {
+			dx, dy := target.X()-pos.X(), target.Y()-pos.Y()
+			if d := math.Hypot(dx, dy); d > 0 {
+				vel = floatgeom.Point2{
+					vel.X() + (dx/d*shotSpeed-vel.X())*homingTurn,
+					vel.Y() + (dy/d*shotSpeed-vel.Y())*homingTurn,
+				}
+				// Turning doesn't slow the shot down
+				if m := math.Hypot(vel.X(), vel.Y()); m > 0 {
+					vel = floatgeom.Point2{vel.X() / m * shotSpeed, vel.Y() / m * shotSpeed}
+				}
+			}
+		}
+		ts := timescale.EnemyAt(pos)
+		prd.ShiftPos(vel.X()*ts, vel.Y()*ts)
+		return 0
+	}, "EnterFrame")
+}
+
+// rangedBehavior walks until the party is in range, then warns and shoots
+func rangedBehavior(kind Shot) *Behavior {
+	return &Behavior{
+		Start: Patrol,
+		States: map[State]StateDef{
+			Patrol: {
+				Enter: Walk,
+				Transitions: []Transition{
+					{To: Telegraph, When: All(After(1500*time.Millisecond), Near(shotRange))},
+				},
+			},
+			Telegraph: {
+				Enter: Both(Stand, Flash(shotColors[kind])),
+				Transitions: []Transition{
+					{To: Idle, When: After(500 * time.Millisecond)},
+				},
+			},
+			// Shooting, then standing a moment after
+			Idle: {
+				Enter: Both(Stand, Shoot(kind)),
+				Transitions: []Transition{
+					{To: Patrol, When: After(300 * time.Millisecond)},
+				},
+			},
+			Stunned: StunnedState(Patrol),
+		},
+		Any: Stunnable,
+	}
+}
+
+// initShots loads what enemies shoot
+func initShots() {
+	shotSheet, err := render.LoadSprites(filepath.Join("assets", "images"), filepath.Join("16x16", "fireball.png"), 16, 16, 0)
+	dlog.ErrorCheck(err)
+	for kind, c := range shotColors {
+		sp := shotSheet[0][0].Copy().(*render.Sprite)
+		sp.Filter(recolor.WithStrategy(recolor.ColorMix(c)))
+		shotSprites[kind] = sp
+	}
+}
@@ -8,15 +8,6 @@ import (
 	"github.com/oakmound/weekly87/internal/recolor"
 )
 
-type enemyType int
-
-const (
-	Hare   enemyType = iota
-	Mantis enemyType = iota
-	Tree   enemyType = iota
-	TypeLimit
-)
-
 type Variant func(*Constructor)
 
 const (
@@ -87,15 +78,11 @@ func changeSize(mult float64) func(c *Constructor) {
 
 const VariantCount = lastSize * lastColor
 
-var enemyTypeList = [TypeLimit]enemyType{
-	Hare,
-	Mantis,
-	Tree,
-}
-
 // Init to be run after oak setup to make sure that enemies have assets and constructors set up
 func Init() {
-	initHare()
-	initMantis()
-	initTree()
+	initShots()
+	// Sections are filled from these constructors, so a run can't start without them
+	if err := loadDefinitions(); err != nil {
+		panic(err)
+	}
 }
@@ -18,6 +18,10 @@ const (
 	Ornament
 	EffectsPlayer
 	EffectsEnemy
+	// EnemyAttack is on what enemies fire at the party
+	EnemyAttack
+	// Loot is on what enemies drop that isn't a chest or a buff
+	Loot
 )
 
 var ColorMap = map[collision.Label]color.RGBA{
@@ -30,4 +34,6 @@ var ColorMap = map[collision.Label]color.RGBA{
 	Drinkable:    color.RGBA{180, 70, 70, 200},
 	Ornament:     color.RGBA{250, 200, 40, 255},
 	NPC:          color.RGBA{125, 200, 10, 255},
+	EnemyAttack:  color.RGBA{255, 60, 0, 255},
+	Loot:         color.RGBA{230, 190, 40, 255},
 }
@@ -0,0 +1,85 @@
+package players
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Adventurer is a uniquely named member of the inn's roster who can be hired into the party
+type Adventurer struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Class int    `json:"class"`
+	// Portrait is the color the adventurer is shown with in listings
+	Portrait      color.RGBA `json:"portrait"`
+	RunsSurvived  int        `json:"runsSurvived"`
+	ChestsCarried int        `json:"chestsCarried"`
+}
+
+// Member returns the party member to store for this adventurer
+func (a Adventurer) Member() PartyMember {
+	return PartyMember{
+		PlayerClass: a.Class,
+		Name:        a.Name,
+		ID:          a.ID,
+	}
+}
+
+var (
+	firstNames = []string{
+		"Dan", "Ada", "Bram", "Cass", "Dorn", "Edda", "Fenn", "Gil",
+		"Hild", "Ivo", "Jory", "Kell", "Lune", "Mott", "Nessa", "Orrin",
+		"Pim", "Quill", "Rook", "Sabe", "Tam", "Ulla", "Vex", "Wren",
+	}
+	epithets = []string{
+		"Bold", "Unlucky", "Greedy", "Swift", "Slow", "Lost", "Loud", "Brave",
+		"Tall", "Short", "Hungry", "Clumsy", "Lucky", "Grim", "Cheerful", "Tired",
+	}
+)
+
+// GenerateAdventurer makes a new adventurer of the given class. The name will not be one of those
+// in taken if that can be helped.
+func GenerateAdventurer(rng *rand.Rand, id int64, class int, taken map[string]bool) Adventurer {
+	name := ""
+	for tries := 0; tries < 20; tries++ {
+		name = firstNames[rng.Intn(len(firstNames))] + " the " + epithets[rng.Intn(len(epithets))]
+		if !taken[name] {
+			break
+		}
+	}
+	if taken[name] {
+		// Everyone's favorite names are gone, start numbering
+		base := name
+		for i := 2; taken[name]; i++ {
+			name = base + " " + romanNumeral(i)
+		}
+	}
+	return Adventurer{
+		ID:    id,
+		Name:  name,
+		Class: class,
+		Portrait: color.RGBA{
+			uint8(60 + rng.Intn(196)),
+			uint8(60 + rng.Intn(196)),
+			uint8(60 + rng.Intn(196)),
+			255,
+		},
+	}
+}
+
+func romanNumeral(n int) string {
+	numerals := []struct {
+		v int
+		s string
+	}{
+		{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+	out := ""
+	for _, nm := range numerals {
+		for n >= nm.v {
+			out += nm.s
+			n -= nm.v
+		}
+	}
+	return out
+}
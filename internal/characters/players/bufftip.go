@@ -0,0 +1,65 @@
+package players
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/oakmound/oak/mouse"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/layer"
+)
+
+const (
+	buffTipW = 320
+	buffTipH = 18
+)
+
+// bindBuffTips shows what a buff does, and how much of it is left,
+// while the mouse is over its icon
+func (p *Party) bindBuffTips() {
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Color = render.FontColor("White")
+	fnt.Size = 12
+	text := fnt.Generate().NewStrText("", 0, 0)
+	bkg := render.NewColorBox(buffTipW, buffTipH, color.RGBA{20, 20, 20, 200})
+	shown := false
+
+	p.CheckedBind(func(p *Party, _ interface{}) int {
+		me := mouse.LastEvent
+		x, y := float64(me.X()), float64(me.Y())
+		tip := ""
+		var tipX, tipY float64
+		now := time.Now()
+		for _, ply := range p.Players {
+			ply.BuffLock.Lock()
+			for _, b := range ply.Buffs {
+				bx, by := b.R.X(), b.R.Y()
+				if x >= bx && x < bx+float64(abilities.BuffIconSize) &&
+					y >= by && y < by+float64(abilities.BuffIconSize) {
+					tip = b.Tooltip(now)
+					// Buff icons are on the right of the screen, so the tip goes to their left
+					tipX, tipY = bx-buffTipW-4, by
+				}
+			}
+			ply.BuffLock.Unlock()
+		}
+		if tip == "" {
+			if shown {
+				text.Undraw()
+				bkg.Undraw()
+				shown = false
+			}
+			return 0
+		}
+		text.SetString(tip)
+		bkg.SetPos(tipX, tipY)
+		text.SetPos(tipX+4, tipY+3)
+		if !shown {
+			render.Draw(bkg, layer.UI, 12)
+			render.Draw(text, layer.UI, 13)
+			shown = true
+		}
+		return 0
+	}, "EnterFrame")
+}
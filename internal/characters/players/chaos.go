@@ -0,0 +1,66 @@
+package players
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/recolor"
+)
+
+// chaosModels are the classes whose models can be handed out in a chaos run.
+// This is a slice rather than a walk over classmapping so that the same seed
+// always gives the same party.
+var chaosModels = []int{
+	Swordsman,
+	Berserker,
+	Paladin,
+	Spearman,
+	Mage,
+	WhiteMage,
+	BlueMage,
+	TimeMage,
+}
+
+// chaosAbilities are the abilities that can be handed out in a chaos run
+var chaosAbilities []abilities.Ability
+
+// chaosInit sets up the pool of chaos abilities, run after abilities are initialized.
+// Every registered ability is in the pool, in name order so seeds stay stable.
+func chaosInit() {
+	chaosAbilities = []abilities.Ability{}
+	for _, name := range abilities.Names() {
+		chaosAbilities = append(chaosAbilities, abilities.Named(name))
+	}
+}
+
+// ChaosConstructor creates the character types from a PartyMember list like ClassConstructor
+// but gives each member a random model, color and pair of abilities.
+// The same seed will always produce the same party.
+func ChaosConstructor(partyComp []PartyMember, seed int64) []Constructor {
+	rng := rand.New(rand.NewSource(seed))
+	classes := make([]Constructor, len(partyComp))
+	for i, c := range partyComp {
+		model := chaosModels[rng.Intn(len(chaosModels))]
+		cons := classmapping[model].Copy()
+
+		tint := color.RGBA{
+			uint8(rng.Intn(256)),
+			uint8(rng.Intn(256)),
+			uint8(rng.Intn(256)),
+			uint8(60 + rng.Intn(100)),
+		}
+		cons.AnimationMap = filterCharMap(cons.AnimationMap, recolor.WithStrategy(recolor.ColorMix(tint)))
+
+		// No duplicate abilities for one character
+		picks := rng.Perm(len(chaosAbilities))
+		cons.Special1 = chaosAbilities[picks[0]]
+		cons.Special2 = chaosAbilities[picks[1]]
+
+		cons.Name = c.Name
+		cons.AccruedValue = c.AccruedValue
+		cons.Class = model
+		classes[i] = *cons
+	}
+	return classes
+}
@@ -0,0 +1,169 @@
+package players
+
+import (
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/alg/floatgeom"
+)
+
+// Shape of a formation
+type Shape int
+
+// Formation shapes
+const (
+	// Column is single file, each member trailing the one ahead by PlayerGap
+	Column Shape = iota
+	// Line is abreast, members stacked above and below the leader
+	Line
+	// Wedge puts the leader at the tip with everyone else fanning out behind
+	Wedge
+	lastShape
+)
+
+// String names the shape
+func (s Shape) String() string {
+	switch s {
+	case Line:
+		return "Line"
+	case Wedge:
+		return "Wedge"
+	}
+	return "Column"
+}
+
+// Next cycles to the following shape
+func (s Shape) Next() Shape {
+	return (s + 1) % lastShape
+}
+
+// Formation is how the party arranges itself while running.
+// It is saved alongside the party composition.
+type Formation struct {
+	Shape Shape `json:"shape"`
+	// Order is the marching order, Order[0] is the index of the player in the lead
+	Order []int `json:"order"`
+}
+
+// offset returns how far behind the leader and how far below the leader
+// the member at the given rank of the marching order stands
+func (s Shape) offset(rank int) (back, side float64) {
+	if rank == 0 {
+		return 0, 0
+	}
+	// Alternate members above and below the leader
+	sign := 1.0
+	if rank%2 == 1 {
+		sign = -1.0
+	}
+	pair := float64((rank + 1) / 2)
+	switch s {
+	case Line:
+		return float64(rank) * 8, sign * pair * 36
+	case Wedge:
+		return pair * PlayerGap * .6, sign * pair * 24
+	}
+	return float64(rank) * PlayerGap, 0
+}
+
+// normalize makes sure the formation's marching order covers exactly n players
+func (f Formation) normalize(n int) Formation {
+	valid := len(f.Order) == n
+	seen := make(map[int]bool, n)
+	for _, idx := range f.Order {
+		if idx < 0 || idx >= n || seen[idx] {
+			valid = false
+			break
+		}
+		seen[idx] = true
+	}
+	if valid {
+		return f
+	}
+	f.Order = make([]int, n)
+	for i := range f.Order {
+		f.Order[i] = i
+	}
+	return f
+}
+
+// Rotate the marching order, sending the leader to the back
+func (f *Formation) Rotate() {
+	if len(f.Order) < 2 {
+		return
+	}
+	f.Order = append(f.Order[1:], f.Order[0])
+}
+
+// rankOf returns where the given player is in the marching order
+func (f Formation) rankOf(idx int) int {
+	for r, i := range f.Order {
+		if i == idx {
+			return r
+		}
+	}
+	return 0
+}
+
+// facingSign is 1 when the party is heading right and -1 when heading left
+func (p *Party) facingSign() float64 {
+	if p.Players[0].facing == "LT" {
+		return -1
+	}
+	return 1
+}
+
+// front finds where the leader of the formation should stand based on player 0,
+// who is the one the party is steered by
+func (p *Party) front() floatgeom.Point2 {
+	back, side := p.Formation.Shape.offset(p.Formation.rankOf(0))
+	p0 := p.Players[0]
+	return floatgeom.Point2{p0.X() + p.facingSign()*back, p0.Y() - side}
+}
+
+// arrange places every member of the party around the given front position,
+// keeping the whole formation within the run's walkable area
+func (p *Party) arrange(front floatgeom.Point2) {
+	_, h := p.Players[0].Swtch.GetDims()
+	minY := float64(oak.ScreenHeight) * 1 / 3
+	maxY := float64(oak.ScreenHeight) - float64(h)
+	minSide, maxSide := 0.0, 0.0
+	for r := range p.Formation.Order {
+		_, side := p.Formation.Shape.offset(r)
+		if side < minSide {
+			minSide = side
+		}
+		if side > maxSide {
+			maxSide = side
+		}
+	}
+	y := front.Y()
+	if y+minSide < minY {
+		y = minY - minSide
+	} else if y+maxSide > maxY {
+		y = maxY - maxSide
+	}
+	for r, idx := range p.Formation.Order {
+		back, side := p.Formation.Shape.offset(r)
+		pl := p.Players[idx]
+		pl.Vector.SetPos(front.X()-p.facingSign()*back, y+side)
+	}
+}
+
+// SetShape changes the shape of the formation mid-run
+func (p *Party) SetShape(s Shape) {
+	front := p.front()
+	p.Formation.Shape = s
+	p.arrange(front)
+}
+
+// RotateOrder sends the leader to the back of the marching order mid-run
+func (p *Party) RotateOrder() {
+	front := p.front()
+	p.Formation.Rotate()
+	p.arrange(front)
+}
+
+// rearBack is how far behind the leader the last member of the marching order is
+func (p *Party) rearBack() float64 {
+	back, _ := p.Formation.Shape.offset(len(p.Formation.Order) - 1)
+	return back
+}
@@ -26,6 +26,7 @@ func Init() {
 		BlueMage:  mageConstructors["Blue"],
 		TimeMage:  mageConstructors["Time"],
 	}
+	chaosInit()
 }
 func filterCharMap(baseCharMap map[string]render.Modifiable, filter mod.Filter) map[string]render.Modifiable {
 	outputMap := make(map[string]render.Modifiable)
@@ -61,10 +62,62 @@ func ClassConstructor(partyComp []PartyMember) []Constructor {
 	classes := make([]Constructor, len(partyComp))
 	for i, c := range partyComp {
 		classes[i] = *classmapping[c.PlayerClass].Copy()
+		classes[i].Name = c.Name
+		classes[i].AccruedValue = c.AccruedValue
+		classes[i].Class = c.PlayerClass
 	}
 	return classes
 }
 
+// ClassAbilities returns the abilities a class is hired with
+func ClassAbilities(class int) []abilities.Ability {
+	cons, ok := classmapping[class]
+	if !ok {
+		return nil
+	}
+	abs := []abilities.Ability{}
+	for _, a := range []abilities.Ability{cons.Special1, cons.Special2} {
+		if a != nil {
+			abs = append(abs, a)
+		}
+	}
+	return abs
+}
+
+// classPassives are the passives each class can choose between, the first being the default
+var classPassives = map[int][]string{
+	Swordsman: {"Thorns", "Marching Song"},
+	Berserker: {"Thorns", "Lodestone"},
+	Paladin:   {"Marching Song", "Thorns"},
+	Spearman:  {"Lodestone", "Marching Song"},
+	Mage:      {"Quickening", "Lodestone"},
+	WhiteMage: {"Marching Song", "Quickening"},
+	BlueMage:  {"Quickening", "Thorns"},
+	TimeMage:  {"Quickening", "Lodestone"},
+}
+
+// ClassPassives returns the names of the passives a class can choose between
+func ClassPassives(class int) []string {
+	return classPassives[class]
+}
+
+var classNames = map[int]string{
+	Swordsman: "Swordsman",
+	Berserker: "Berserker",
+	Paladin:   "Paladin",
+	Spearman:  "Spearman",
+	Mage:      "Mage",
+	WhiteMage: "White Mage",
+	BlueMage:  "Blue Mage",
+	TimeMage:  "Time Mage",
+	InnKeeper: "Innkeeper",
+}
+
+// ClassName returns the display name of a class
+func ClassName(class int) string {
+	return classNames[class]
+}
+
 // ClassDefinition specifies what makes a class special!
 type ClassDefinition struct {
 	Name        string
@@ -78,4 +131,6 @@ type PartyMember struct {
 	PlayerClass  int
 	AccruedValue int
 	Name         string
+	// ID of the adventurer on the roster
+	ID int64
 }
@@ -9,6 +9,11 @@ type Buffer interface {
 	Buffs() []buff.Buff
 }
 
+// A Pickup is taken by whoever touches it
+type Pickup interface {
+	PickedUp()
+}
+
 // A Destroyable can be destroyed
 type Destroyable interface {
 	Destroy()
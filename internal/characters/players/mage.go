@@ -27,8 +27,8 @@ func mageInit() {
 			LayerColors: map[string]color.RGBA{
 				"clothes": color.RGBA{47, 47, 200, 200},
 			},
-			Special1: abilities.FrostBolt,
-			Special2: abilities.Blizzard,
+			Special1: abilities.Named("FrostBolt"),
+			Special2: abilities.Named("Blizzard"),
 		},
 		{
 			Name: "White",
@@ -43,8 +43,8 @@ func mageInit() {
 			LayerColors: map[string]color.RGBA{
 				"clothes": color.RGBA{180, 70, 70, 180},
 			},
-			Special1: abilities.Fireball,
-			Special2: abilities.FireStorm,
+			Special1: abilities.Named("Fireball"),
+			Special2: abilities.Named("FireStorm"),
 		},
 		{
 			Name: "Time",
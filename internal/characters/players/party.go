@@ -27,7 +27,10 @@ import (
 	"github.com/oakmound/weekly87/internal/characters/doodads"
 	"github.com/oakmound/weekly87/internal/characters/enemies"
 	"github.com/oakmound/weekly87/internal/characters/labels"
+	"github.com/oakmound/weekly87/internal/characters/status"
+	"github.com/oakmound/weekly87/internal/coop"
 	"github.com/oakmound/weekly87/internal/joys"
+	"github.com/oakmound/weekly87/internal/timescale"
 	"github.com/oakmound/weekly87/internal/vfx"
 )
 
@@ -39,6 +42,13 @@ type Party struct {
 	speedUps     float64
 	joystickID   uint32
 	Debug        bool
+	steerer      int
+	steerSwapAt  time.Time
+	// seats are the local players that joined when the party set out
+	seats     []coop.Seat
+	Formation Formation
+	// Coins are picked up from fallen enemies, and are added to wealth with the chests
+	Coins int64
 }
 
 // Init the party giving them a CID
@@ -58,6 +68,27 @@ func (p *Party) SpeedUp(n float64) {
 
 }
 
+// Seats returns the local players that joined when the party set out
+func (p *Party) Seats() []coop.Seat {
+	return p.seats
+}
+
+// steerTurn is how long each local player steers for when taking turns
+const steerTurn = 10 * time.Second
+
+// Steerer returns which of the local players' seats is steering the party
+func (p *Party) Steerer(seatCount int) int {
+	if !coop.TakeTurns || seatCount < 2 {
+		return 0
+	}
+	if time.Now().After(p.steerSwapAt) {
+		p.steerer = (p.steerer + 1) % seatCount
+		p.steerSwapAt = time.Now().Add(steerTurn)
+		event.Trigger("SteererChanged", p.steerer)
+	}
+	return p.steerer % seatCount
+}
+
 // CheckedBind wraps binding to the party performing our standard checks
 func (p *Party) CheckedBind(bnd func(*Party, interface{}) int, ev string) {
 	p.Bind(func(id int, data interface{}) int {
@@ -70,12 +101,41 @@ func (p *Party) CheckedBind(bnd func(*Party, interface{}) int, ev string) {
 	}, ev)
 }
 
+// ChestWeight is how much each point of carried chest value slows the party
+const ChestWeight = .02
+
+// maxBurden caps how much carried chests can slow the party
+const maxBurden = .5
+
+// Burden is the fraction of run speed the party keeps while carrying its chests
+func (p *Party) Burden() float64 {
+	weight := 0.0
+	for _, pl := range p.Players {
+		for _, v := range pl.ChestValues {
+			weight += float64(v) * ChestWeight
+		}
+	}
+	return 1 - math.Min(weight, maxBurden)
+}
+
 // RunSpeed retrieves the current speed for the party to run at
 func (p *Party) RunSpeed() int {
+	swift := 1 + buff.SwiftRate*float64(p.Swift())
 	if p.Players[0].facing == "LT" {
-		return int(p.Players[0].RunSpeed - p.Acceleration)
+		return int((p.Players[0].RunSpeed - p.Acceleration) * p.Burden() * swift)
 	}
-	return int(p.Players[len(p.Players)-1].RunSpeed + p.Acceleration)
+	return int((p.Players[len(p.Players)-1].RunSpeed + p.Acceleration) * p.Burden() * swift)
+}
+
+// DropChest has the rearmost carrier in the marching order drop their top chest
+func (p *Party) DropChest() bool {
+	for r := len(p.Formation.Order) - 1; r >= 0; r-- {
+		pl := p.Players[p.Formation.Order[r]]
+		if pl.Alive && pl.DropChest() {
+			return true
+		}
+	}
+	return false
 }
 
 // Speed returns the party's speed vector
@@ -108,6 +168,7 @@ type PartyConstructor struct {
 	Players    []Constructor
 	Bindings   map[string]func(*Party, interface{}) int
 	MaxPlayers int
+	Formation  Formation
 }
 
 // NewRunningParty creates a party for the run scene
@@ -147,6 +208,8 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 		p := Player{}
 		p.PartyIndex = i
 		p.Status = &buff.Status{}
+		p.upgrades = pcon.Upgrades
+		p.Passive = pcon.Passive
 
 		if pcon.Special1 != nil {
 			p.Special1 = pcon.Special1.SetUser(&p)
@@ -208,6 +271,7 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 				sfx.Play("bounced1")
 				// Affect the enemy
 				en.PushBack(physics.NewVector(pushD, 0))
+				en.CID.Trigger("Attacked", status.Attack{Element: status.Shield})
 
 				source := vfx.PushBack1().Generate(2)
 				plyX := ply.X() - 5
@@ -230,35 +294,45 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 					return 0
 				}, "EnterFrame")
 
-				// Remove the charge from our buffs
-				for buffIdx, b := range ply.Buffs {
-					if b.Name == buff.NameShield {
-						b.Charges--
-						if b.Charges <= 0 {
-							b.ExpireAt = time.Now()
-						}
-						ply.Buffs[buffIdx] = b
-
-						//TODO: Consider have shields create different pushbacks
+				// The blow knocks the top chest loose
+				ply.DropChest()
+				event.Trigger("PlayerHit", nil)
 
-						return
-					}
+				// Remove the charge from our buffs
+				if ply.SpendCharge(buff.IDShield) {
+					//TODO: Consider have shields create different pushbacks
+					return
 				}
-				dlog.Warn("We thought we had shield but we could not find a buff with such a name")
+				dlog.Warn("We thought we had shield but we could not find a shield buff")
 				return
 			}
 
-			abilities.Produce(
-				abilities.StartAt(floatgeom.Point2{ply.X() + 8, ply.Y() + 10}),
-				//abilities.FollowSpeed(ply.Delta.Xp(), ply.Delta.Yp()),
-				abilities.WithParticles(vfx.WhiteRing()),
-				abilities.Duration(time.Millisecond*20),
-			)
-			vfx.SmallShaker.Shake(time.Duration(1000) * time.Millisecond)
-			sfx.Play("playerHit1")
-
-			ply.Kill()
-			event.Trigger("PlayerDeath", nil)
+			ply.struck(en.Name())
+			// Some enemies feed on the kill
+			en.CID.Trigger("Fed", nil)
+		})
+		// Interaction with what enemies shoot
+		p.RSpace.Add(labels.EnemyAttack, shotBy)
+
+		// Hitting Coins
+		p.RSpace.Add(labels.Loot, func(s, s2 *collision.Space) {
+			p, ok := s.CID.E().(*Player)
+			if !ok {
+				dlog.Error("Non-player sent to player binding")
+				return
+			}
+			l, ok := s2.CID.E().(*doodads.Loot)
+			if !ok {
+				dlog.Error("Non-loot sent to loot binding")
+				return
+			}
+			if !l.Active || p.Party == nil {
+				return
+			}
+			p.Party.Coins += l.Value
+			sfx.Play("chestHop1")
+			l.PickedUp()
+			l.Destroy()
 		})
 
 		// Hitting Chests
@@ -291,6 +365,7 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 
 			ch.Destroy()
 
+			event.Trigger("ChestTaken", []int64{ch.SectionID, ch.Idx})
 			event.Trigger("RunBackOnce", nil)
 		})
 
@@ -314,7 +389,7 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 			}
 			bfs := bfr.Buffs()
 			for _, b := range bfs {
-				if b.Name == buff.NameRez {
+				if b.ID == buff.IDRez {
 					for _, ply := range pty.Players {
 						if !ply.Alive {
 							ply.Revive()
@@ -333,6 +408,9 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 					}
 				}
 			}
+			if pk, ok := bfr.(Pickup); ok {
+				pk.PickedUp()
+			}
 			if dstr, ok := bfr.(Destroyable); ok {
 				dstr.Destroy()
 			}
@@ -361,6 +439,8 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 	}
 
 	pty.CID = pty.Init()
+	pty.steerSwapAt = time.Now().Add(steerTurn)
+	pty.seats = coop.Seats()
 
 	lowestID := joys.LowestID()
 	if lowestID != math.MaxInt32 {
@@ -369,21 +449,26 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 	if unmoving {
 		return pty, nil
 	}
+
+	// Line the formation up so the last in the marching order is by the wall
+	pty.Formation = pc.Formation.normalize(len(pty.Players))
+	start := pc.Players[0].Position
+	pty.arrange(floatgeom.Point2{start.X() + pty.rearBack(), start.Y()})
+
 	pty.CheckedBind(func(pty *Party, _ interface{}) int {
-		for i, p := range pty.Players {
+		for _, p := range pty.Players {
 			// Lean towards being generous
 			p.AddBuff(buff.Invulnerable(render.NewColorBox(8, 8, color.RGBA{255, 255, 0, 255}), 5*time.Second))
-			i := i
 			p.RunSpeed *= -1
-			p.CheckedBind(func(p *Player, _ interface{}) int {
-				// Shift the player back until against the right wall
-				if int(p.X())-oak.ViewPos.X >= oak.ScreenWidth-(WallOffset+(len(pty.Players)-1-i)*PlayerGap) {
-					return event.UnbindSingle
-				}
-				p.ShiftX(float64(-pty.RunSpeed()) * 2)
-				return 0
-			}, "EnterFrame")
 		}
+		pty.CheckedBind(func(pty *Party, _ interface{}) int {
+			// Shift the party back until the rear of the formation is against the right wall
+			if int(pty.front().X())-oak.ViewPos.X >= oak.ScreenWidth-(WallOffset+int(pty.rearBack())) {
+				return event.UnbindSingle
+			}
+			pty.Players[0].ShiftX(float64(-pty.RunSpeed()) * 2)
+			return 0
+		}, "EnterFrame")
 		return event.UnbindSingle
 	}, "RunBack")
 
@@ -398,6 +483,49 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 		return 0
 	}, "Rez")
 
+	pty.CheckedBind(func(pty *Party, data interface{}) int {
+		rw, ok := data.(abilities.Rewind)
+		if !ok {
+			dlog.Warn("Data sent on cooldown rewind was not in the right format")
+			return 0
+		}
+		for _, p := range pty.Players {
+			if !p.Alive {
+				continue
+			}
+			for _, a := range []abilities.Ability{p.Special1, p.Special2} {
+				if a != nil && a != rw.Except {
+					a.Rewind(rw.By)
+				}
+			}
+		}
+		return 0
+	}, "CooldownRewind")
+
+	pty.CheckedBind(func(pty *Party, _ interface{}) int {
+		for _, p := range pty.Players {
+			if !p.Alive {
+				continue
+			}
+			for _, a := range []abilities.Ability{p.Special1, p.Special2} {
+				if a != nil {
+					a.Killed()
+				}
+			}
+		}
+		return 0
+	}, "EnemyDeath")
+
+	pty.CheckedBind(func(pty *Party, _ interface{}) int {
+		now := timescale.Now()
+		for _, p := range pty.Players {
+			p.updatePassive(now)
+		}
+		return 0
+	}, "EnterFrame")
+
+	pty.bindBuffTips()
+
 	buffIcon, err := render.LoadSprite(filepath.Join("assets/images", "16x16"), "place_holder_buff.png")
 	dlog.ErrorCheck(err)
 
@@ -416,29 +544,23 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 
 		p0.Delta.SetX(float64(pty.RunSpeed()))
 		if p0.Status.Rage <= 0 {
-			if oak.IsDown(key.UpArrow) || js.StickLY > 8000 {
-				p0.Delta.ShiftY(-pty.Speed().Y())
-			}
-			if oak.IsDown(key.DownArrow) || js.StickLY < -8000 {
-				p0.Delta.ShiftY(pty.Speed().Y())
+			if len(pty.seats) > 1 {
+				p0.Delta.ShiftY(pty.seats[pty.Steerer(len(pty.seats))].Vertical() * pty.Speed().Y())
+			} else {
+				if oak.IsDown(key.UpArrow) || js.StickLY > 8000 {
+					p0.Delta.ShiftY(-pty.Speed().Y())
+				}
+				if oak.IsDown(key.DownArrow) || js.StickLY < -8000 {
+					p0.Delta.ShiftY(pty.Speed().Y())
+				}
 			}
 		}
 
 		p0.Vector.Add(p0.Delta)
 
-		_, h := p0.Swtch.GetDims()
-		hf := float64(h)
-		if p0.Vector.Y() < float64(oak.ScreenHeight)*1/3 {
-			p0.Vector.SetY(float64(oak.ScreenHeight) * 1 / 3)
-		} else if p0.Vector.Y() > (float64(oak.ScreenHeight) - hf) {
-			p0.Vector.SetY((float64(oak.ScreenHeight) - hf))
-		}
+		// Everyone holds their place in the formation around player 0
+		pty.arrange(pty.front())
 
-		for i := 1; i < len(pty.Players); i++ {
-			p := pty.Players[i]
-			p.Vector.ShiftX(p0.Delta.X())
-			p.Vector.SetY(p0.Vector.Y())
-		}
 		flashStartTime := time.Now().Add(time.Second * 5)
 		flashCounter := 5
 		for _, p := range pty.Players {
@@ -446,12 +568,12 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 			// flawed when they're all working together--we only want
 			// to shift everything -once-, otherwise there are jitters
 			// or other awkward bits to moving around.
-			p.R.SetPos(p.Vector.X(), p0.Vector.Y())
+			p.R.SetPos(p.Vector.X(), p.Vector.Y())
 
 			for len(p.Buffs) > 0 {
 				if p.Buffs[0].ExpireAt.Before(time.Now()) {
 					p.BuffLock.Lock()
-					p.Buffs[0].Disable(p.Status)
+					p.Buffs[0].End(p.Status)
 					p.Buffs[0].R.Undraw()
 					p.Buffs = p.Buffs[1:]
 					p.BuffLock.Unlock()
@@ -482,7 +604,7 @@ func (pc *PartyConstructor) NewParty(unmoving bool) (*Party, error) {
 			if !p.Alive {
 				continue
 			}
-			p.RSpace.Update(p.Vector.X(), p0.Vector.Y(), p.RSpace.GetW(), p.RSpace.GetH())
+			p.RSpace.Update(p.Vector.X(), p.Vector.Y(), p.RSpace.GetW(), p.RSpace.GetH())
 			<-p.RSpace.CallOnHits()
 		}
 
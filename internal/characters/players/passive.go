@@ -0,0 +1,57 @@
+package players
+
+import (
+	"time"
+
+	"github.com/oakmound/weekly87/internal/abilities/buff"
+)
+
+// passiveTargets are the statuses the player's passive changes
+func (p *Player) passiveTargets() []*buff.Status {
+	if !p.Passive.PartyWide || p.Party == nil {
+		return []*buff.Status{p.Status}
+	}
+	sts := make([]*buff.Status, len(p.Party.Players))
+	for i, ply := range p.Party.Players {
+		sts[i] = ply.Status
+	}
+	return sts
+}
+
+// updatePassive turns the player's passive on or off as they live and die,
+// and ticks it while it is on
+func (p *Player) updatePassive(now time.Time) {
+	if p.Passive == nil {
+		return
+	}
+	if p.Alive != p.passiveOn {
+		p.passiveOn = p.Alive
+		for _, s := range p.passiveTargets() {
+			if p.passiveOn && p.Passive.Enable != nil {
+				p.Passive.Enable(s)
+			} else if !p.passiveOn && p.Passive.Disable != nil {
+				p.Passive.Disable(s)
+			}
+		}
+		p.nextPassiveTick = now.Add(p.Passive.Interval)
+	}
+	if !p.passiveOn || p.Passive.Tick == nil || p.Passive.Interval <= 0 {
+		return
+	}
+	if now.Before(p.nextPassiveTick) {
+		return
+	}
+	p.nextPassiveTick = now.Add(p.Passive.Interval)
+	p.Passive.Tick(p)
+}
+
+// Swift is the most Swift of the living members of the party
+func (p *Party) Swift() int {
+	swift := 0
+	for _, ply := range p.Players {
+		if ply.Alive && ply.Status.Swift > swift {
+			swift = ply.Status.Swift
+		}
+	}
+	return swift
+}
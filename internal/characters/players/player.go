@@ -11,6 +11,7 @@ import (
 	"github.com/oakmound/weekly87/internal/abilities"
 	"github.com/oakmound/weekly87/internal/abilities/buff"
 	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/sfx"
 
 	"github.com/oakmound/oak/dlog"
 
@@ -51,6 +52,12 @@ type Constructor struct {
 	RunSpeed     float64
 	Name         string
 	AccruedValue int
+	// Class the character was built from, in chaos runs the class of its model
+	Class int
+	// Upgrades bought for this character's abilities
+	Upgrades []string
+	// Passive is always on while the character is alive
+	Passive *abilities.Passive
 }
 
 // Copy returns a shallow copy of the constructor.
@@ -68,6 +75,9 @@ func (pc *Constructor) Copy() *Constructor {
 		RunSpeed:     pc.RunSpeed,
 		Name:         pc.Name,
 		AccruedValue: pc.AccruedValue,
+		Class:        pc.Class,
+		Upgrades:     pc.Upgrades,
+		Passive:      pc.Passive,
 	}
 }
 
@@ -90,7 +100,19 @@ type Player struct {
 	BuffLock     sync.Mutex
 	Buffs        []buff.Buff
 	*buff.Status
-	Party *Party
+	Party    *Party
+	upgrades []string
+	// Passive is on while the player is alive
+	Passive         *abilities.Passive
+	passiveOn       bool
+	nextPassiveTick time.Time
+	// KilledBy names what last killed the player
+	KilledBy string
+}
+
+// Upgrades lists the upgrades bought for the player's abilities
+func (p *Player) Upgrades() []string {
+	return p.upgrades
 }
 
 // DebugEnabled checks if the party is in debug mode
@@ -103,41 +125,113 @@ func (p *Player) GetDelta() physics.Vector {
 	return p.Party.Players[0].Delta
 }
 
-// AddBuff to the player!
+// AddBuff to the player! A buff with the same ID as one the player has
+// stacks onto it instead of being added separately.
 func (p *Player) AddBuff(b buff.Buff) {
+	now := time.Now()
 	p.BuffLock.Lock()
-	b.ExpireAt = time.Now().Add(b.Duration)
+	if b.ID != buff.Unidentified {
+		for i := range p.Buffs {
+			if p.Buffs[i].ID != b.ID {
+				continue
+			}
+			enable := p.Buffs[i].Merge(b, now)
+			// Restarted buffs stop flickering
+			p.Buffs[i].PreExpireCounter = 0
+			p.Buffs[i].R.Set("base")
+			p.sortBuffs()
+			p.BuffLock.Unlock()
+			if enable {
+				b.Enable(p.Status)
+			}
+			p.ReorderBuffs()
+			return
+		}
+	}
+	b.ExpireAt = now.Add(b.Duration)
+	b.Stacks = 1
 	b.R = buff.BasicBuffSwitch(b.RGen())
 	p.Buffs = append(p.Buffs, b)
 	if p.Alive {
 		render.Draw(b.R, layer.UI, 10)
 	}
-	sort.Slice(p.Buffs, func(i, j int) bool {
-		return p.Buffs[i].ExpireAt.Before(p.Buffs[j].ExpireAt)
-	})
+	p.sortBuffs()
 	p.BuffLock.Unlock()
 	b.Enable(p.Status)
 	p.ReorderBuffs()
 
 }
 
-// DropChest if the player has one
-func (p *Player) DropChest() {
-	if len(p.ChestValues) == 0 {
-		return
+// sortBuffs by expiry time, soonest first. The buff lock should be held.
+func (p *Player) sortBuffs() {
+	sort.Slice(p.Buffs, func(i, j int) bool {
+		return p.Buffs[i].ExpireAt.Before(p.Buffs[j].ExpireAt)
+	})
+}
+
+// SpendCharge uses up a charge of the player's buff with the given ID, ending the
+// buff once it has no charges left. Returns false if the player has no such buff.
+func (p *Player) SpendCharge(id buff.ID) bool {
+	p.BuffLock.Lock()
+	defer p.BuffLock.Unlock()
+	for i := range p.Buffs {
+		if p.Buffs[i].ID != id {
+			continue
+		}
+		p.Buffs[i].Charges--
+		if p.Buffs[i].Charges <= 0 {
+			p.Buffs[i].ExpireAt = time.Now()
+			p.sortBuffs()
+		}
+		return true
 	}
+	return false
+}
+
+// ChestToss is how far ahead of the party a dropped chest lands
+const ChestToss = 120
 
-	_, h := p.Chests[len(p.Chests)].GetDims()
+// DroppedChest is sent along with "ChestDropped" when a player loses a chest
+type DroppedChest struct {
+	Value int64
+	Pos   floatgeom.Point2
+}
+
+// DropChest puts the top chest the player is carrying back into the world. Returns false if the player had no chest.
+func (p *Player) DropChest() bool {
+	if len(p.ChestValues) == 0 {
+		return false
+	}
+	top := len(p.Chests) - 1
+	_, h := p.Chests[top].GetDims()
+	p.Chests[top].Undraw()
 	p.ChestsHeight -= float64(h)
-	p.ChestValues = p.ChestValues[:len(p.ChestValues)-1]
-	p.Chests = p.Chests[:len(p.Chests)-1]
+	value := p.ChestValues[top]
+	p.ChestValues = p.ChestValues[:top]
+	p.Chests = p.Chests[:top]
+
+	// Toss it out in front of the party so it has to be steered into to be picked back up
+	x := p.X()
+	if p.Party != nil {
+		x = p.Party.front().X()
+	}
+	toss := float64(ChestToss)
+	if p.facing == "LT" {
+		toss *= -1
+	}
+	sfx.Play("chestHop1")
+	event.Trigger("ChestDropped", DroppedChest{
+		Value: value,
+		Pos:   floatgeom.Point2{x + toss, p.Y()},
+	})
 
 	if len(p.ChestValues) > 0 {
-		return
+		return true
 	}
-	p.Swtch.Set("walkLT")
+	dlog.ErrorCheck(p.Swtch.Set("walk" + p.facing))
 	p.Special1.Enable(true)
 	p.Special2.Enable(true)
+	return true
 }
 
 // AddChest to those carried by the player
@@ -209,7 +303,7 @@ func (p *Player) Kill() {
 	p.Chests = []render.Renderable{}
 	p.BuffLock.Lock()
 	for _, b := range p.Buffs {
-		b.Disable(p.Status)
+		b.End(p.Status)
 		b.R.Undraw()
 	}
 	p.Buffs = []buff.Buff{}
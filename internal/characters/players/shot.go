@@ -0,0 +1,62 @@
+package players
+
+import (
+	"time"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/collision"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/abilities/buff"
+	"github.com/oakmound/weekly87/internal/sfx"
+	"github.com/oakmound/weekly87/internal/vfx"
+)
+
+// struck kills the player, as an enemy would. by names what killed them, and is
+// sent along with the death.
+func (ply *Player) struck(by string) {
+	ply.KilledBy = by
+	abilities.Produce(
+		abilities.StartAt(floatgeom.Point2{ply.X() + 8, ply.Y() + 10}),
+		//abilities.FollowSpeed(ply.Delta.Xp(), ply.Delta.Yp()),
+		abilities.WithParticles(vfx.WhiteRing()),
+		abilities.Duration(time.Millisecond*20),
+	)
+	vfx.SmallShaker.Shake(time.Duration(1000) * time.Millisecond)
+	sfx.Play("playerHit1")
+
+	ply.Kill()
+	event.Trigger("PlayerHit", nil)
+	event.Trigger("PlayerDeath", by)
+}
+
+// shotBy is what happens when something an enemy fired hits a player.
+// Invulnerable players let it pass, shields block it, otherwise it hits like an enemy.
+func shotBy(s, shot *collision.Space) {
+	ply, ok := s.CID.E().(*Player)
+	if !ok {
+		dlog.Error("Non-player sent to player binding")
+		return
+	}
+	if !ply.Alive || ply.Invulnerable > 0 {
+		return
+	}
+	if d, ok := shot.CID.E().(Destroyable); ok {
+		defer d.Destroy()
+	}
+	if ply.Shield > 0 {
+		vfx.VerySmallShaker.Shake(time.Duration(400) * time.Millisecond)
+		sfx.Play("bounced1")
+		event.Trigger("PlayerHit", nil)
+		if !ply.SpendCharge(buff.IDShield) {
+			dlog.Warn("We thought we had shield but we could not find a shield buff")
+		}
+		return
+	}
+	by := ""
+	if prd, ok := shot.CID.E().(*abilities.Product); ok {
+		by = prd.FiredBy()
+	}
+	ply.struck(by)
+}
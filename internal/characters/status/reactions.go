@@ -0,0 +1,151 @@
+package status
+
+import (
+	"image/color"
+	"time"
+)
+
+// An Element tags an attack so it can react with what already afflicts its target
+type Element int
+
+const (
+	NoElement Element = iota
+	Fire
+	Frost
+	Force
+	Shield
+	ElementLimit
+)
+
+// Any matches a target no matter what it is marked with
+const Any Element = -1
+
+var elementNames = [ElementLimit]string{
+	Fire:   "fire",
+	Frost:  "frost",
+	Force:  "force",
+	Shield: "shield",
+}
+
+// ElementNamed returns the element with the given name
+func ElementNamed(name string) (Element, bool) {
+	for e, n := range elementNames {
+		if n != "" && n == name {
+			return Element(e), true
+		}
+	}
+	return NoElement, false
+}
+
+func (e Element) String() string {
+	if e <= NoElement || e >= ElementLimit {
+		return "none"
+	}
+	return elementNames[e]
+}
+
+// markTime is the least time an element marks its target for
+const markTime = 1500 * time.Millisecond
+
+// A Reaction happens when an attack of one element hits a target marked by another
+type Reaction struct {
+	Name string
+	// Hit is the element of the incoming attack
+	Hit Element
+	// On is the element the target has to be marked with, or Any
+	On Element
+	// Removes the mark of On and these effects from the target
+	Removes []Kind
+	// Attack is made on the target
+	Attack Attack
+	// Splash is made on the enemies within Radius of the target
+	Splash Attack
+	Radius float64
+	// Color of the burst shown when the reaction happens
+	Color color.RGBA
+}
+
+// Reactions are checked in order whenever an attack with an element lands
+var Reactions = []Reaction{
+	{
+		Name:    "Shatter",
+		Hit:     Fire,
+		On:      Frost,
+		Removes: []Kind{Slow},
+		Attack:  Attack{Damage: 2},
+		Splash:  Attack{Damage: 2},
+		Radius:  64,
+		Color:   color.RGBA{180, 220, 255, 255},
+	},
+	{
+		Name: "Ignite",
+		Hit:  Fire,
+		On:   Force,
+		Attack: Attack{Effects: []Effect{
+			{Kind: Burn, Duration: 4 * time.Second, Strength: 1},
+			{Kind: Burn, Duration: 4 * time.Second, Strength: 1},
+			{Kind: Burn, Duration: 4 * time.Second, Strength: 1},
+		}},
+		Splash: Attack{Effects: []Effect{{Kind: Burn, Duration: 3 * time.Second, Strength: .5}}},
+		Radius: 40,
+		Color:  color.RGBA{255, 140, 20, 255},
+	},
+	{
+		Name:    "Brittle",
+		Hit:     Force,
+		On:      Frost,
+		Removes: []Kind{Slow},
+		Attack: Attack{Damage: 1, Effects: []Effect{
+			{Kind: Vulnerable, Duration: 4 * time.Second, Strength: .5},
+		}},
+		Color: color.RGBA{200, 200, 255, 255},
+	},
+	{
+		Name:   "Bounce",
+		Hit:    Shield,
+		On:     Any,
+		Attack: Attack{Effects: []Effect{{Kind: Stun, Duration: time.Second}}},
+		Color:  color.RGBA{255, 255, 150, 255},
+	},
+}
+
+// Marked reports whether the target was recently hit by the element
+func (s *Set) Marked(e Element, now time.Time) bool {
+	return e > NoElement && e < ElementLimit && s.marks[e].After(now)
+}
+
+// React returns the reactions an attack sets off on the target, then marks the target
+// with the attack's element
+func (s *Set) React(atk Attack, now time.Time) []Reaction {
+	if atk.Element <= NoElement || atk.Element >= ElementLimit {
+		return nil
+	}
+	var reactions []Reaction
+	for _, r := range Reactions {
+		if r.Hit != atk.Element {
+			continue
+		}
+		if r.On != Any {
+			if !s.Marked(r.On, now) {
+				continue
+			}
+			if len(r.Removes) != 0 {
+				s.marks[r.On] = time.Time{}
+				for _, k := range r.Removes {
+					s.active[k] = nil
+				}
+			}
+		}
+		reactions = append(reactions, r)
+	}
+	until := now.Add(markTime)
+	for _, e := range atk.Effects {
+		if end := now.Add(e.Duration); end.After(until) {
+			until = end
+		}
+	}
+	if until.After(s.marks[atk.Element]) {
+		s.marks[atk.Element] = until
+	}
+	return reactions
+}
@@ -0,0 +1,240 @@
+package status
+
+import (
+	"image/color"
+	"time"
+)
+
+// A Kind of status effect
+type Kind int
+
+const (
+	None Kind = iota
+	Slow
+	Burn
+	Poison
+	Stun
+	Vulnerable
+	KindLimit
+)
+
+// Stacking is how an effect combines with one of its kind that is already on a target
+type Stacking int
+
+const (
+	// Refresh restarts the effect, keeping the stronger of the two
+	Refresh Stacking = iota
+	// Stack adds a stack, up to the limit, and restarts every stack
+	Stack
+	// Independent stacks each run out on their own, the oldest is replaced at the limit
+	Independent
+)
+
+// A Rule is how a kind of effect behaves
+type Rule struct {
+	Name      string
+	Stacking  Stacking
+	MaxStacks int
+	// Tick is how often damage is dealt over time, if at all
+	Tick time.Duration
+	// Tint is mixed into the target's colors while the effect lasts
+	Tint color.RGBA
+}
+
+// Rules for each kind of effect. Earlier kinds take precedence when picking a tint.
+var Rules = [KindLimit]Rule{
+	Slow:       {Name: "slow", Stacking: Refresh, MaxStacks: 1, Tint: color.RGBA{100, 150, 255, 150}},
+	Burn:       {Name: "burn", Stacking: Stack, MaxStacks: 3, Tick: time.Second, Tint: color.RGBA{255, 120, 40, 150}},
+	Poison:     {Name: "poison", Stacking: Independent, MaxStacks: 5, Tick: time.Second, Tint: color.RGBA{80, 220, 80, 150}},
+	Stun:       {Name: "stun", Stacking: Refresh, MaxStacks: 1, Tint: color.RGBA{255, 255, 120, 150}},
+	Vulnerable: {Name: "vulnerable", Stacking: Refresh, MaxStacks: 1, Tint: color.RGBA{200, 80, 200, 150}},
+}
+
+// Named returns the kind of effect with the given name
+func Named(name string) (Kind, bool) {
+	for k, r := range Rules {
+		if r.Name != "" && r.Name == name {
+			return Kind(k), true
+		}
+	}
+	return None, false
+}
+
+func (k Kind) String() string {
+	if k <= None || k >= KindLimit {
+		return "none"
+	}
+	return Rules[k].Name
+}
+
+// An Effect is applied to what an attack hits
+type Effect struct {
+	Kind     Kind
+	Duration time.Duration
+	// Strength means something different for each kind.
+	// Slow: the fraction of speed lost
+	// Burn and Poison: damage each tick for each stack
+	// Vulnerable: the fraction of extra damage taken
+	// Stun ignores it
+	Strength float64
+}
+
+// An Attack is sent to enemies on "Attacked"
+type Attack struct {
+	Damage   float64
+	Pushback float64
+	Effects  []Effect
+	// Element can set off reactions with what the target was marked by before
+	Element Element
+}
+
+// active is an effect on a target
+type active struct {
+	Effect
+	// ends holds when each stack runs out
+	ends     []time.Time
+	nextTick time.Time
+}
+
+// A Set holds the effects on a target
+type Set struct {
+	active [KindLimit]*active
+	// marks hold until when the target counts as hit by each element
+	marks [ElementLimit]time.Time
+}
+
+// Apply an effect to the set following the rules of its kind
+func (s *Set) Apply(e Effect, now time.Time) {
+	if e.Kind <= None || e.Kind >= KindLimit || e.Duration <= 0 {
+		return
+	}
+	rule := Rules[e.Kind]
+	end := now.Add(e.Duration)
+	a := s.active[e.Kind]
+	if a == nil {
+		a = &active{Effect: e, ends: []time.Time{end}}
+		if rule.Tick > 0 {
+			a.nextTick = now.Add(rule.Tick)
+		}
+		s.active[e.Kind] = a
+		return
+	}
+	switch rule.Stacking {
+	case Refresh:
+		// The stronger application holds, for as long as it was applied for. A weaker
+		// one can't stretch it out, and only a later equal one lengthens it.
+		if e.Strength > a.Strength || (e.Strength == a.Strength && end.After(a.ends[0])) {
+			a.Strength = e.Strength
+			a.ends[0] = end
+		}
+		return
+	}
+	if e.Strength > a.Strength {
+		a.Strength = e.Strength
+	}
+	switch rule.Stacking {
+	case Stack:
+		if len(a.ends) < rule.MaxStacks {
+			a.ends = append(a.ends, end)
+		}
+		for i := range a.ends {
+			a.ends[i] = end
+		}
+	case Independent:
+		if len(a.ends) < rule.MaxStacks {
+			a.ends = append(a.ends, end)
+			return
+		}
+		oldest := 0
+		for i, t := range a.ends {
+			if t.Before(a.ends[oldest]) {
+				oldest = i
+			}
+		}
+		a.ends[oldest] = end
+	}
+}
+
+// Update runs out expired effects and returns the damage dealt by ticks up to now
+func (s *Set) Update(now time.Time) float64 {
+	dmg := 0.0
+	for k, a := range s.active {
+		if a == nil {
+			continue
+		}
+		if tick := Rules[k].Tick; tick > 0 {
+			for !now.Before(a.nextTick) {
+				// Stacks that ran out before this tick don't deal damage
+				for _, end := range a.ends {
+					if end.After(a.nextTick) {
+						dmg += a.Strength
+					}
+				}
+				a.nextTick = a.nextTick.Add(tick)
+			}
+		}
+		ends := a.ends[:0]
+		for _, end := range a.ends {
+			if end.After(now) {
+				ends = append(ends, end)
+			}
+		}
+		a.ends = ends
+		if len(a.ends) == 0 {
+			s.active[k] = nil
+		}
+	}
+	return dmg
+}
+
+// Has reports whether an effect of the kind is on the target
+func (s *Set) Has(k Kind) bool {
+	return k > None && k < KindLimit && s.active[k] != nil
+}
+
+// Stacks returns how many stacks of the kind are on the target
+func (s *Set) Stacks(k Kind) int {
+	if !s.Has(k) {
+		return 0
+	}
+	return len(s.active[k].ends)
+}
+
+// SpeedScale is how fast the target can move, from 0 to 1
+func (s *Set) SpeedScale() float64 {
+	if s.Has(Stun) {
+		return 0
+	}
+	if s.Has(Slow) {
+		return 1 - clamp(s.active[Slow].Strength)
+	}
+	return 1
+}
+
+// DamageScale multiplies the damage the target takes
+func (s *Set) DamageScale() float64 {
+	if s.Has(Vulnerable) {
+		return 1 + s.active[Vulnerable].Strength
+	}
+	return 1
+}
+
+// Tint is the kind of effect whose tint the target should show, or None
+func (s *Set) Tint() Kind {
+	for k := Kind(1); k < KindLimit; k++ {
+		if s.Has(k) {
+			return k
+		}
+	}
+	return None
+}
+
+func clamp(f float64) float64 {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
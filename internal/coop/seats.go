@@ -0,0 +1,143 @@
+// Package coop tracks the local players that have joined a game and what input each one uses
+package coop
+
+import (
+	"image/color"
+	"sync"
+
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/key"
+	"github.com/oakmound/weekly87/internal/joys"
+)
+
+// MaxSeats is the most local players that can join, one per party member
+const MaxSeats = 4
+
+// InputKind is the sort of input a seat is controlled with
+type InputKind int
+
+// Input kinds
+const (
+	KeyboardRight InputKind = iota
+	KeyboardLeft
+	Joystick
+)
+
+// Seat is a single local player
+type Seat struct {
+	Kind  InputKind
+	JoyID uint32
+}
+
+var (
+	// The right half of the keyboard is always seated so solo play works as before
+	seats    = []Seat{{Kind: KeyboardRight}}
+	seatLock sync.Mutex
+
+	// TakeTurns makes steering the party rotate between seats instead of staying with the first
+	TakeTurns bool
+)
+
+// Seat colors, used to tell who controls which party member
+var seatColors = [MaxSeats]color.RGBA{
+	{80, 64, 34, 128},
+	{34, 64, 120, 128},
+	{34, 110, 40, 128},
+	{120, 34, 90, 128},
+}
+
+// Seats returns a copy of the currently joined seats.
+// Joysticks that have been unplugged leave their seat.
+func Seats() []Seat {
+	seatLock.Lock()
+	defer seatLock.Unlock()
+	kept := seats[:0]
+	for _, s := range seats {
+		if s.Kind == Joystick && !joys.Connected(s.JoyID) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	seats = kept
+	out := make([]Seat, len(seats))
+	copy(out, seats)
+	return out
+}
+
+// Toggle joins the seat if it is not yet seated, or has it leave if it is.
+// Returns whether the seat is now joined.
+func Toggle(s Seat) bool {
+	seatLock.Lock()
+	defer seatLock.Unlock()
+	for i, st := range seats {
+		if st == s {
+			// The first seat can't leave, otherwise nobody is in control
+			if i == 0 {
+				return true
+			}
+			seats = append(seats[:i], seats[i+1:]...)
+			return false
+		}
+	}
+	if len(seats) >= MaxSeats {
+		return false
+	}
+	seats = append(seats, s)
+	return true
+}
+
+// Color for the seat at the given index
+func Color(i int) color.RGBA {
+	return seatColors[i%MaxSeats]
+}
+
+// Vertical returns the steering input of the seat, negative for up
+func (s Seat) Vertical() float64 {
+	switch s.Kind {
+	case KeyboardRight:
+		if oak.IsDown(key.UpArrow) {
+			return -1
+		}
+		if oak.IsDown(key.DownArrow) {
+			return 1
+		}
+	case KeyboardLeft:
+		if oak.IsDown(key.A) {
+			return -1
+		}
+		if oak.IsDown(key.Z) {
+			return 1
+		}
+	case Joystick:
+		js := joys.StickState(s.JoyID)
+		if js.StickLY > 8000 {
+			return -1
+		}
+		if js.StickLY < -8000 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// AbilityKeys returns the key events that trigger the first and second ability for a keyboard seat
+func (s Seat) AbilityKeys() (string, string) {
+	switch s.Kind {
+	case KeyboardRight:
+		return key.Down + key.K, key.Down + key.L
+	case KeyboardLeft:
+		return key.Down + key.S, key.Down + key.X
+	}
+	return "", ""
+}
+
+// String names the controls for the seat
+func (s Seat) String() string {
+	switch s.Kind {
+	case KeyboardRight:
+		return "Arrows+K/L"
+	case KeyboardLeft:
+		return "A/Z+S/X"
+	}
+	return "Joystick"
+}
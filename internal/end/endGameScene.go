@@ -99,6 +99,12 @@ var Scene = scene.Scene{
 		if sc > r.FarthestGoneInSections {
 			r.FarthestGoneInSections = sc
 		}
+		if runInfo.Chaos && !justVisiting {
+			r.ChaosRuns++
+			if sc > r.FarthestChaosInSections {
+				r.FarthestChaosInSections = sc
+			}
+		}
 
 		// Display variables
 		currentDeathToll := r.Deaths
@@ -120,9 +126,38 @@ var Scene = scene.Scene{
 			}
 		}
 
+		if !justVisiting {
+			// Keep whatever marching order the party ended the run in
+			r.Formation = runInfo.Party.Formation
+
+			// The dead leave the roster for good
+			fallen := []*players.Player{}
+			ids := []int64{}
+			for i, pl := range runInfo.Party.Players {
+				if i >= len(r.PartyComp) {
+					break
+				}
+				if pl.Alive {
+					r.Survived(r.PartyComp[i].ID, len(pl.ChestValues))
+				} else {
+					fallen = append(fallen, pl)
+					ids = append(ids, r.PartyComp[i].ID)
+				}
+			}
+			for i, id := range ids {
+				r.Bury(id, runInfo.SectionsCleared, fallen[i].KilledBy)
+			}
+
+			r.Bestiary.Merge(runInfo.Bestiary)
+		}
+
 		// For the next run TODO: move to run
 		r.BaseSeed = int64(runInfo.SectionsCleared) + 1
 
+		if !justVisiting {
+			// Coins from fallen enemies are shared by the party
+			chestTotal += int(runInfo.Party.Coins)
+		}
 		r.Wealth += chestTotal
 		r.EnemiesDefeated += runInfo.EnemiesDefeated
 
@@ -187,6 +222,8 @@ var Scene = scene.Scene{
 
 		}
 
+		drawMemorial(r.Memorial)
+
 		goldPit := floatgeom.NewRect2WH(670, float64(oak.ScreenHeight)-100, 330, 100)
 		makeGoldParticles(r.Wealth, goldPit)
 
@@ -203,6 +240,12 @@ var Scene = scene.Scene{
 
 		textY += 40
 
+		if runInfo.Chaos {
+			fnt.Color = render.FontColor("Red")
+			chaosText := fnt.Generate().NewStrText("Chaos Run! Seed "+strconv.FormatInt(runInfo.ChaosSeed, 10), textX-100, textY-20)
+			render.Draw(chaosText, 2, 2)
+		}
+
 		titling := blueFnt.NewStrText("Last Run Info:", textX, textY)
 		textX += 120
 		render.Draw(titling, 2, 2)
@@ -0,0 +1,48 @@
+package end
+
+import (
+	"image"
+	"strconv"
+
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/records"
+)
+
+// memorialShown is how many of the most recently fallen are listed by the graves
+const memorialShown = 8
+
+// drawMemorial lists the most recently fallen adventurers above the graveyard
+func drawMemorial(memorial []records.Fallen) {
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Size = 12
+	fnt.Color = render.FontColor("Blue")
+
+	x := 16.0
+	y := graveY - 130
+	render.Draw(fnt.Generate().NewStrText("In Memoriam ("+strconv.Itoa(len(memorial))+")", x, y), layer.UI, 2)
+
+	start := len(memorial) - memorialShown
+	if start < 0 {
+		start = 0
+	}
+	// Most recent first
+	for i := len(memorial) - 1; i >= start; i-- {
+		f := memorial[i]
+		y += 14
+		swatch := render.NewColorBox(8, 8, f.Portrait)
+		swatch.SetPos(x, y+2)
+		render.Draw(swatch, layer.UI, 2)
+
+		fnt.Color = image.NewUniform(f.Portrait)
+		txt := f.Name + ", " + players.ClassName(f.Class) +
+			": survived " + strconv.Itoa(f.RunsSurvived) +
+			", carried " + strconv.Itoa(f.ChestsCarried) +
+			", fell at " + strconv.Itoa(f.DiedIn)
+		if f.KilledBy != "" {
+			txt += " to a " + f.KilledBy
+		}
+		render.Draw(fnt.Generate().NewStrText(txt, x+12, y), layer.UI, 2)
+	}
+}
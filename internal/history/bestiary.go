@@ -0,0 +1,88 @@
+package history
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/oak/render/mod"
+	"github.com/oakmound/weekly87/internal/characters/enemies"
+	"github.com/oakmound/weekly87/internal/records"
+)
+
+const (
+	bestiaryIconSize = 40.0
+	bestiaryMaxRowH  = 56.0
+	bestiaryLineH    = 15.0
+)
+
+// drawBestiary shows every kind of enemy, with what is known about those that have been seen
+func drawBestiary(r *records.Records, fnt *render.FontGenerator) {
+	x := float64(oak.ScreenWidth)*5/12 + 10
+	y := 80.0
+	w := float64(oak.ScreenWidth) - x - float64(oak.ScreenWidth)/18
+	h := float64(oak.ScreenHeight * 3 / 5)
+
+	backing := render.NewColorBox(int(w), int(h), color.RGBA{120, 120, 120, 210})
+	backing.SetPos(x, y)
+	render.Draw(backing, 1)
+
+	fnt = fnt.Copy()
+	fnt.Size = 18
+	title := fnt.Generate().NewStrText("Bestiary", x+10, y+8)
+	render.Draw(title, 2, 2)
+	fnt.Size = 14
+	nameFnt := fnt.Generate()
+	fnt.Size = 11
+	noteFnt := fnt.Generate()
+
+	if enemies.TypeLimit == 0 {
+		return
+	}
+	rowY := y + 36
+	rowH := math.Min(bestiaryMaxRowH, (h-40)/float64(enemies.TypeLimit))
+	for typ := 0; typ < enemies.TypeLimit; typ++ {
+		name := enemies.TypeName(typ)
+		bst, seen := r.Bestiary[name]
+
+		cons := enemies.Constructors[typ*enemies.VariantCount]
+		if anim, ok := cons.AnimationMap["walkLT"]; ok {
+			icon := anim.Copy()
+			if iw, ih := icon.GetDims(); iw > 0 && ih > 0 {
+				if scale := bestiaryIconSize / math.Max(float64(iw), float64(ih)); scale < 1 {
+					icon = icon.Modify(mod.Scale(scale, scale))
+				}
+			}
+			if !seen {
+				// Enemies that haven't been seen are only shadows
+				icon.Filter(mod.Fade(200))
+			}
+			icon.SetPos(x+10, rowY)
+			render.Draw(icon, 2, 1)
+		}
+
+		textX := x + 20 + bestiaryIconSize
+		if !seen {
+			render.Draw(nameFnt.NewStrText("???", textX, rowY), 2, 2)
+			rowY += rowH
+			continue
+		}
+		stats := name +
+			"  Kills: " + strconv.FormatInt(bst.Kills, 10) +
+			"  Deaths caused: " + strconv.FormatInt(bst.DeathsCaused, 10) +
+			"  Deepest: " + strconv.FormatInt(bst.DeepestSeen, 10)
+		render.Draw(nameFnt.NewStrText(stats, textX, rowY), 2, 2)
+		if notes := enemies.TypeNotes(typ); notes != "" {
+			render.Draw(noteFnt.NewStrText(notes, textX, rowY+bestiaryLineH), 2, 2)
+		}
+		seenWith := "Variants: " + strings.Join(bst.Variants, ", ")
+		if len(bst.Affixes) != 0 {
+			seenWith += "  Affixes: " + strings.Join(bst.Affixes, ", ")
+		}
+		render.Draw(noteFnt.NewStrText(seenWith, textX, rowY+bestiaryLineH*2), 2, 2)
+		rowY += rowH
+	}
+}
@@ -75,6 +75,15 @@ var Scene = scene.Scene{
 		render.Draw(farthestText, 2, 2)
 		textY += 40
 
+		chaosRuns := strconv.FormatInt(r.ChaosRuns, 10)
+		chaosFarthest := strconv.FormatInt(r.FarthestChaosInSections, 10)
+		chaosText := blueFnt.NewStrText("Chaos Runs: "+chaosRuns+"  Farthest: "+chaosFarthest, textX, textY)
+		chaosText.Center()
+		render.Draw(chaosText, 2, 2)
+		textY += 40
+
+		drawBestiary(r, fnt)
+
 		newSavePressed := 0
 		newSaveStr := "Are you sure"
 
@@ -201,7 +201,7 @@ func (n *NPC) FaceLeft(shouldFaceLeft bool) *NPC {
 // newInnNPCBasic sets up the basics for an npc in the inn but does not set any ai/bindings
 // Safety to allow for reuse between special npc types
 func newInnNPCBasic(class int, scale, x, y float64) *NPC {
-	pcon := players.ClassConstructor([]players.PartyMember{{class, 0, "NPC How did you find me"}})[0]
+	pcon := players.ClassConstructor([]players.PartyMember{{PlayerClass: class, Name: "NPC How did you find me"}})[0]
 	n := &NPC{}
 	n.Class = class
 	n.Swtch = render.NewSwitch("standRT", pcon.AnimationMap).Copy().(*render.Switch)
@@ -0,0 +1,123 @@
+package inn
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/collision"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/joystick"
+	"github.com/oakmound/oak/key"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/menus"
+	"github.com/oakmound/weekly87/internal/menus/selector"
+	"github.com/oakmound/weekly87/internal/records"
+	"github.com/oakmound/weekly87/internal/sfx"
+)
+
+// A hire is who joins the party once a place has been picked for them
+type hire func() players.PartyMember
+
+// pendingHire is someone picked off the hiring board who still needs a place in the party
+type pendingHire struct {
+	npc  *NPC
+	hire hire
+}
+
+// adventurerLine describes an adventurer on the roster for the hiring board
+func adventurerLine(a players.Adventurer) string {
+	return a.Name + " - Runs survived: " + strconv.Itoa(a.RunsSurvived) +
+		"  Chests carried: " + strconv.Itoa(a.ChestsCarried)
+}
+
+// hiringBoard lets one of the free adventurers of a class on the roster be picked to
+// join the party, or someone new be hired. chosen is called with the pick once the
+// board is closed, cancelled if it is closed without one.
+func hiringBoard(rec *records.Records, class int, chosen func(hire), cancelled func()) {
+	free := rec.Free(class)
+	hires := make([]hire, 0, len(free)+1)
+	for _, a := range free {
+		mem := a.Member()
+		hires = append(hires, func() players.PartyMember { return mem })
+	}
+	hires = append(hires, func() players.PartyMember { return rec.HireNew(class) })
+
+	bkg := render.NewColorBox(boardW, int(boardHeader+boardLineH*float64(len(hires))+10), color.RGBA{40, 30, 20, 230})
+	bkg.SetPos(boardX, boardY)
+	render.Draw(bkg, layer.UI, 4)
+
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Color = render.FontColor("White")
+	fnt.Size = 12
+	title := fnt.Generate().NewStrText("Hire a "+players.ClassName(class)+"  (Space to choose, Esc to leave)", boardX+10, boardY+8)
+	render.Draw(title, layer.UI, 5)
+
+	lines := make([]*render.Text, len(hires))
+	spcs := make([]*collision.Space, len(hires))
+	for i := range hires {
+		y := boardY + boardHeader + float64(i)*boardLineH
+		if i < len(free) {
+			// Adventurers are shown in the color they are known by
+			fnt.Color = image.NewUniform(free[i].Portrait)
+			lines[i] = fnt.Generate().NewStrText(adventurerLine(free[i]), boardX+14, y+4)
+		} else {
+			fnt.Color = render.FontColor("White")
+			lines[i] = fnt.Generate().NewStrText("Someone new", boardX+14, y+4)
+		}
+		render.Draw(lines[i], layer.UI, 5)
+		spcs[i] = collision.NewUnassignedSpace(boardX+6, y, boardW-12, boardLineH)
+	}
+
+	var picked hire
+	var sl *selector.Selector
+	sl, _ = selector.New(
+		selector.Layers(layer.UI, 6),
+		selector.VertArrowControl(),
+		selector.JoystickVertDpadControl(),
+		selector.Spaces(spcs...),
+		selector.Callback(func(i int, data ...interface{}) {
+			if len(data) == 0 || picked != nil {
+				return
+			}
+			sfx.Play("selected")
+			picked = hires[i]
+			sl.Destroy()
+		}),
+		selector.Cleanup(func(int) {
+			bkg.Undraw()
+			title.Undraw()
+			for _, l := range lines {
+				l.Undraw()
+			}
+			if picked != nil {
+				chosen(picked)
+			} else {
+				cancelled()
+			}
+		}),
+		selector.InteractTrigger(key.Down+key.Spacebar, "hire"),
+		selector.InteractTrigger("A"+joystick.ButtonUp, "hire"),
+		selector.DestroyTrigger(key.Down+key.Escape),
+		selector.DestroyTrigger("B"+joystick.ButtonUp),
+		selector.MouseBindings(true),
+		selector.MouseLeft(selector.MouseInteract("hire")),
+		selector.MouseRight(func(s *selector.Selector, _ int) int {
+			s.Destroy()
+			return 0
+		}),
+		selector.Display(func(pt floatgeom.Point2) render.Renderable {
+			poly, err := render.NewPolygon(
+				floatgeom.Point2{0, 0},
+				floatgeom.Point2{pt.X(), 0},
+				floatgeom.Point2{pt.X(), pt.Y()},
+				floatgeom.Point2{0, pt.Y()},
+			)
+			dlog.ErrorCheck(err)
+			return poly.GetThickOutline(menus.Green, 1)
+		}),
+	)
+}
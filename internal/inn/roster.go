@@ -0,0 +1,34 @@
+package inn
+
+import (
+	"image"
+	"strings"
+
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/records"
+)
+
+// nameTags draws the first name of each adventurer in the party, in their portrait color,
+// under where they stand in the party selection box
+func nameTags(rec *records.Records, x, y float64) []render.Renderable {
+	tags := []render.Renderable{}
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Size = 10
+	for i, m := range rec.PartyComp {
+		if m.PlayerClass == players.Empty {
+			continue
+		}
+		if a, ok := rec.Adventurer(m.ID); ok {
+			fnt.Color = image.NewUniform(a.Portrait)
+		} else {
+			fnt.Color = render.FontColor("White")
+		}
+		first := strings.SplitN(m.Name, " ", 2)[0]
+		t := fnt.Generate().NewStrText(first, x+float64(i)*players.PlayerGap, y)
+		render.Draw(t, layer.UI, 3)
+		tags = append(tags, t)
+	}
+	return tags
+}
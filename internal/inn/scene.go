@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"math/rand"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/oakmound/weekly87/internal/characters/doodads"
 	"github.com/oakmound/weekly87/internal/characters/labels"
 	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/coop"
 	"github.com/oakmound/weekly87/internal/dtools"
 	"github.com/oakmound/weekly87/internal/keyviz"
 	"github.com/oakmound/weekly87/internal/layer"
@@ -34,6 +36,7 @@ import (
 	"github.com/oakmound/weekly87/internal/menus/selector"
 	"github.com/oakmound/weekly87/internal/music"
 	"github.com/oakmound/weekly87/internal/records"
+	"github.com/oakmound/weekly87/internal/sfx"
 )
 
 var stayInMenu bool
@@ -104,8 +107,8 @@ var Scene = scene.Scene{
 			NewInnNPC(players.Berserker, npcScale, 445, 460),
 			NewInnNPC(players.BlueMage, npcScale, 241, 210).FaceLeft(true),
 			NewInnNPC(players.Paladin, npcScale, 240, 280).FaceLeft(true),
-			// 	NewInnNPC(players.Spearman, npcScale, 675, 477).FaceLeft(true),
-			// 	NewInnNPC(players.TimeMage, npcScale, 680, 230).FaceLeft(true),
+			NewInnNPC(players.Spearman, npcScale, 675, 477).FaceLeft(true),
+			NewInnNPC(players.TimeMage, npcScale, 680, 230).FaceLeft(true),
 		}
 
 		// Start: Swordsman, size 1 party
@@ -115,15 +118,70 @@ var Scene = scene.Scene{
 		// 45 Sections: Three person party
 		// 75 Sections: BlueMage
 		// 120 Sections: Paladin
+		// 160 Sections: Spearman
+		// 180 Sections: Time Mage
 		// 200 Sections: Four person party
 
 		// Future: More modes
 		// Custom: Choose your own abilities, model, color
-		// Chaos: All abilities, models, colors are random (no duplicate abilities for one char)
 
 		// Inn does quite a few operations on our record (mainly for party purposes)
 		curRecord = records.Load()
 
+		// Chaos: All abilities, models, colors are random (no duplicate abilities for one char)
+		fnt := render.DefFontGenerator.Copy()
+		fnt.Color = render.FontColor("Red")
+		fnt.Size = 14
+		chaosText := fnt.Generate().NewStrText(chaosString(curRecord.ChaosMode), 30, float64(oak.ScreenHeight)-24)
+		render.Draw(chaosText, layer.UI, 1)
+		toggleChaos := func(int, interface{}) int {
+			curRecord.ChaosMode = !curRecord.ChaosMode
+			chaosText.SetString(chaosString(curRecord.ChaosMode))
+			sfx.Play("selected")
+			return 0
+		}
+		event.GlobalBind(toggleChaos, key.Down+key.C)
+		event.GlobalBind(toggleChaos, "Y"+joystick.ButtonUp)
+
+		// Local co-op: extra players join or leave with their own controls
+		seatText := fnt.Generate().NewStrText(seatString(), 230, float64(oak.ScreenHeight)-24)
+		render.Draw(seatText, layer.UI, 1)
+		toggleSeat := func(st coop.Seat) {
+			if coop.Toggle(st) {
+				sfx.Play("selected")
+			} else {
+				sfx.Play("nope1")
+			}
+			seatText.SetString(seatString())
+		}
+		event.GlobalBind(func(_ int, state interface{}) int {
+			jState, ok := state.(*joystick.State)
+			if !ok {
+				return 0
+			}
+			toggleSeat(coop.Seat{Kind: coop.Joystick, JoyID: jState.ID})
+			return 0
+		}, "RightShoulder"+joystick.ButtonUp)
+		event.GlobalBind(func(int, interface{}) int {
+			toggleSeat(coop.Seat{Kind: coop.KeyboardLeft})
+			return 0
+		}, key.Down+key.J)
+		event.GlobalBind(func(int, interface{}) int {
+			coop.TakeTurns = !coop.TakeTurns
+			seatText.SetString(seatString())
+			return 0
+		}, key.Down+key.T)
+
+		// Formation: the shape the party runs in, reordered mid-run
+		formationText := fnt.Generate().NewStrText("Formation: "+curRecord.Formation.Shape.String(), 30, float64(oak.ScreenHeight)-44)
+		render.Draw(formationText, layer.UI, 1)
+		event.GlobalBind(func(int, interface{}) int {
+			curRecord.Formation.Shape = curRecord.Formation.Shape.Next()
+			formationText.SetString("Formation: " + curRecord.Formation.Shape.String())
+			sfx.Play("selected")
+			return 0
+		}, key.Down+key.F)
+
 		charUnlocks := []int{
 			0,
 			3,
@@ -132,6 +190,8 @@ var Scene = scene.Scene{
 			45,
 			75,
 			120,
+			160,
+			180,
 		}
 		progress := len(charUnlocks)
 		for i, cu := range charUnlocks {
@@ -157,7 +217,8 @@ var Scene = scene.Scene{
 		}
 		partySize := len(partySizeUnlocks)
 		for i, psu := range partySizeUnlocks {
-			if int64(psu) > curRecord.SectionsCleared+int64(curRecord.Wealth) {
+			// Spending on upgrades shouldn't shrink the party
+			if int64(psu) > curRecord.SectionsCleared+int64(curRecord.Wealth+curRecord.WealthSpent) {
 				partySize = i
 				break
 			}
@@ -281,18 +342,61 @@ var Scene = scene.Scene{
 			}, "EnterFrame")
 		})
 
+		// Upgrades: Wealth can be spent improving the party's abilities
+		openUpgrades := func(int, interface{}) int {
+			if pc.State != playing {
+				return 0
+			}
+			pc.State = inMenu
+			if !upgradeBoard(curRecord, func() { pc.State = playing }) {
+				pc.State = playing
+				sfx.Play("nope1")
+			}
+			return 0
+		}
+		event.GlobalBind(openUpgrades, key.Down+key.U)
+		event.GlobalBind(openUpgrades, "Back"+joystick.ButtonUp)
+
 		interactLock := &sync.Mutex{}
-		partySelectStart := func(int, interface{}) int {
+		// pending is who was picked off the hiring board, waiting for a place in the party
+		var pending *pendingHire
+		var partySelectStart func(int, interface{}) int
+		partySelectStart = func(int, interface{}) int {
 
 			interactLock.Lock()
-			if lastInteractedNPC == nil {
+			var npc *NPC
+			var hireFn hire
+			if pending != nil {
+				npc, hireFn = pending.npc, pending.hire
+				pending = nil
+			} else if lastInteractedNPC == nil {
 				interactLock.Unlock()
 				return 0
+			} else {
+				npc = lastInteractedNPC
+				lastInteractedNPC = nil
+				npc.Button.Undraw()
 			}
-			npc := lastInteractedNPC
-			lastInteractedNPC = nil
-			npc.Button.Undraw()
 			interactLock.Unlock()
+			if hireFn == nil {
+				class := npc.Class
+				if len(curRecord.Free(class)) != 0 {
+					// Pick who to hire first, then where they go in the party
+					pc.State = inMenu
+					hiringBoard(curRecord, class, func(h hire) {
+						interactLock.Lock()
+						pending = &pendingHire{npc, h}
+						interactLock.Unlock()
+						// Wait a frame, so the key that picked doesn't pick a place too
+						event.GlobalBind(func(int, interface{}) int {
+							partySelectStart(0, nil)
+							return event.UnbindSingle
+						}, "EnterFrame")
+					}, func() { pc.State = playing })
+					return 0
+				}
+				hireFn = func() players.PartyMember { return curRecord.HireNew(class) }
+			}
 			npcW, _ := npc.R.GetDims()
 			bkgW, bkgH := partyBackground.GetDims()
 			// Disable all controls
@@ -322,6 +426,7 @@ var Scene = scene.Scene{
 				render.Draw(bx, layer.UI, 3)
 				crossedOuts = append(crossedOuts, bx)
 			}
+			tags := nameTags(curRecord, partyBackground.X()+16, partyBackground.Y()+44)
 
 			// Show a confirm button, a cancel button and a boot button
 			cnfrm := getConfirmBtn()
@@ -350,7 +455,7 @@ var Scene = scene.Scene{
 						if len(curRecord.PartyComp) <= i {
 							curRecord.PartyComp = append(curRecord.PartyComp, players.PartyMember{})
 						}
-						curRecord.PartyComp[i].PlayerClass = npc.Class
+						curRecord.PartyComp[i] = hireFn()
 						ptycon.Players = players.ClassConstructor(curRecord.PartyComp)
 						return
 					}
@@ -388,6 +493,9 @@ var Scene = scene.Scene{
 					for _, r := range crossedOuts {
 						r.Undraw()
 					}
+					for _, r := range tags {
+						r.Undraw()
+					}
 					cnfrm.Undraw()
 					cancl.Undraw()
 					boot.Undraw()
@@ -442,7 +550,7 @@ var Scene = scene.Scene{
 		// Clear, set and report on the debug commands available
 		oak.ResetCommands()
 		oak.AddCommand("resetParty", func(args []string) {
-			curRecord.PartyComp = []players.PartyMember{{players.Swordsman, 0, "Dan the Almost Default"}}
+			curRecord.PartyComp = []players.PartyMember{curRecord.Hire(players.Swordsman)}
 			ptycon.Players = players.ClassConstructor(curRecord.PartyComp)
 			ptycon.Players[0].Position = ptyOffset
 			for _, p := range pty.Players {
@@ -500,6 +608,32 @@ var Scene = scene.Scene{
 	},
 }
 
+// chaosString describes whether the next run will be a chaos run
+func chaosString(on bool) string {
+	state := "Off"
+	if on {
+		state = "On"
+	}
+	toggle := "C"
+	if oak.MostRecentInput == oak.Joystick {
+		toggle = "Y"
+	}
+	return "Chaos Mode: " + state + " (" + toggle + ")"
+}
+
+// seatString describes who has joined for local co-op
+func seatString() string {
+	seats := coop.Seats()
+	txt := "Players: " + strconv.Itoa(len(seats))
+	for i, st := range seats {
+		txt += "  P" + strconv.Itoa(i+1) + " " + st.String()
+	}
+	if coop.TakeTurns && len(seats) > 1 {
+		txt += "  (Taking turns steering)"
+	}
+	return txt + "  J / Right Shoulder to join, T to take turns"
+}
+
 func getInteractBtn() render.Renderable {
 	// Todo: change to space?
 	txt := "Enter"
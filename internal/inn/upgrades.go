@@ -0,0 +1,185 @@
+package inn
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/collision"
+	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/joystick"
+	"github.com/oakmound/oak/key"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/menus"
+	"github.com/oakmound/weekly87/internal/menus/selector"
+	"github.com/oakmound/weekly87/internal/records"
+	"github.com/oakmound/weekly87/internal/sfx"
+)
+
+const (
+	boardX      = 240.0
+	boardY      = 150.0
+	boardW      = 560
+	boardLineH  = 20.0
+	boardHeader = 30.0
+)
+
+// An offer is a line on the upgrade board
+type offer interface {
+	String(rec *records.Records) string
+	// take the offer, returning whether it could be taken
+	take(rec *records.Records) bool
+}
+
+// upgradeOffer is an upgrade bought with Wealth
+type upgradeOffer struct {
+	class   int
+	ability string
+	abilities.Upgrade
+}
+
+func (o upgradeOffer) String(rec *records.Records) string {
+	mark := "[-] "
+	if rec.HasUpgrade(o.class, o.ID) {
+		mark = "[x] "
+	} else if rec.CanUpgrade(o.class, o.Upgrade) {
+		mark = "[ ] "
+	}
+	return mark + players.ClassName(o.class) + " " + o.ability + ": " + o.Name +
+		" (" + strconv.Itoa(o.Cost) + ") - " + o.Description
+}
+
+func (o upgradeOffer) take(rec *records.Records) bool {
+	return rec.BuyUpgrade(o.class, o.Upgrade)
+}
+
+// passiveOffer is a passive a class can choose for free
+type passiveOffer struct {
+	class int
+	*abilities.Passive
+}
+
+func (o passiveOffer) String(rec *records.Records) string {
+	mark := "( ) "
+	if rec.PassiveFor(o.class) == o.Name {
+		mark = "(o) "
+	}
+	return mark + players.ClassName(o.class) + " passive: " + o.Name + " - " + o.Description
+}
+
+func (o passiveOffer) take(rec *records.Records) bool {
+	return rec.ChoosePassive(o.class, o.Name)
+}
+
+// upgradeOffers lists the upgrades for the abilities of each class in the party,
+// and the passives each of those classes can choose between
+func upgradeOffers(rec *records.Records) []offer {
+	offers := []offer{}
+	seen := map[int]bool{}
+	for _, m := range rec.PartyComp {
+		if m.PlayerClass == players.Empty || seen[m.PlayerClass] {
+			continue
+		}
+		seen[m.PlayerClass] = true
+		for _, a := range players.ClassAbilities(m.PlayerClass) {
+			name := abilities.NameOf(a)
+			for _, up := range abilities.UpgradesFor(name) {
+				offers = append(offers, upgradeOffer{class: m.PlayerClass, ability: name, Upgrade: up})
+			}
+		}
+		for _, name := range players.ClassPassives(m.PlayerClass) {
+			if p := abilities.PassiveNamed(name); p != nil {
+				offers = append(offers, passiveOffer{class: m.PlayerClass, Passive: p})
+			}
+		}
+	}
+	return offers
+}
+
+// upgradeBoard lets Wealth be spent on upgrades for the abilities of the classes in the party,
+// and passives be chosen for them. done is called once the board is closed, and the board won't open if there is nothing to buy.
+func upgradeBoard(rec *records.Records, done func()) bool {
+	offers := upgradeOffers(rec)
+	if len(offers) == 0 {
+		return false
+	}
+
+	bkg := render.NewColorBox(boardW, int(boardHeader+boardLineH*float64(len(offers))+10), color.RGBA{40, 30, 20, 230})
+	bkg.SetPos(boardX, boardY)
+	render.Draw(bkg, layer.UI, 4)
+
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Color = render.FontColor("White")
+	fnt.Size = 12
+	font := fnt.Generate()
+
+	title := font.NewStrText("", boardX+10, boardY+8)
+	setTitle := func() {
+		title.SetString("Upgrades & Passives - Wealth: " + strconv.Itoa(rec.Wealth) + "  (Space to choose, Esc to leave)")
+	}
+	setTitle()
+	render.Draw(title, layer.UI, 5)
+
+	lines := make([]*render.Text, len(offers))
+	spcs := make([]*collision.Space, len(offers))
+	for i, o := range offers {
+		y := boardY + boardHeader + float64(i)*boardLineH
+		lines[i] = font.NewStrText(o.String(rec), boardX+14, y+4)
+		render.Draw(lines[i], layer.UI, 5)
+		spcs[i] = collision.NewUnassignedSpace(boardX+6, y, boardW-12, boardLineH)
+	}
+
+	selector.New(
+		selector.Layers(layer.UI, 6),
+		selector.VertArrowControl(),
+		selector.JoystickVertDpadControl(),
+		selector.Spaces(spcs...),
+		selector.Callback(func(i int, data ...interface{}) {
+			if len(data) == 0 {
+				return
+			}
+			if !offers[i].take(rec) {
+				sfx.Play("nope1")
+				return
+			}
+			sfx.Play("selected")
+			setTitle()
+			// Buying one upgrade can unlock others, and choosing a passive unchooses the rest
+			for j, o := range offers {
+				lines[j].SetString(o.String(rec))
+			}
+		}),
+		selector.Cleanup(func(int) {
+			bkg.Undraw()
+			title.Undraw()
+			for _, l := range lines {
+				l.Undraw()
+			}
+			done()
+		}),
+		selector.InteractTrigger(key.Down+key.Spacebar, "buy"),
+		selector.InteractTrigger("A"+joystick.ButtonUp, "buy"),
+		selector.DestroyTrigger(key.Down+key.Escape),
+		selector.DestroyTrigger("B"+joystick.ButtonUp),
+		selector.MouseBindings(true),
+		selector.MouseLeft(selector.MouseInteract("buy")),
+		selector.MouseRight(func(s *selector.Selector, _ int) int {
+			s.Destroy()
+			return 0
+		}),
+		selector.Display(func(pt floatgeom.Point2) render.Renderable {
+			poly, err := render.NewPolygon(
+				floatgeom.Point2{0, 0},
+				floatgeom.Point2{pt.X(), 0},
+				floatgeom.Point2{pt.X(), pt.Y()},
+				floatgeom.Point2{0, pt.Y()},
+			)
+			dlog.ErrorCheck(err)
+			return poly.GetThickOutline(menus.Green, 1)
+		}),
+	)
+	return true
+}
@@ -0,0 +1,35 @@
+package inn
+
+import (
+	"testing"
+
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/records"
+)
+
+func TestPassiveOfferTake(t *testing.T) {
+	rec := &records.Records{}
+	passives := players.ClassPassives(players.Swordsman)
+	if len(passives) < 2 {
+		t.Fatalf("Swordsman should choose between passives, has %v", passives)
+	}
+	if rec.PassiveFor(players.Swordsman) != passives[0] {
+		t.Fatalf("expected default passive %q, got %q", passives[0], rec.PassiveFor(players.Swordsman))
+	}
+	o := passiveOffer{class: players.Swordsman, Passive: &abilities.Passive{Name: passives[1]}}
+	if !o.take(rec) {
+		t.Fatalf("taking %q was refused", passives[1])
+	}
+	if got := rec.PassiveFor(players.Swordsman); got != passives[1] {
+		t.Fatalf("expected passive %q after taking it, got %q", passives[1], got)
+	}
+
+	other := passiveOffer{class: players.Swordsman, Passive: &abilities.Passive{Name: "Not A Passive"}}
+	if other.take(rec) {
+		t.Fatal("took a passive the class can't choose")
+	}
+	if got := rec.PassiveFor(players.Swordsman); got != passives[1] {
+		t.Fatalf("refused offer changed passive to %q", got)
+	}
+}
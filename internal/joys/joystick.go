@@ -36,6 +36,14 @@ func StickState(v uint32) joystick.State {
 	return st
 }
 
+// Connected reports whether the requested joystick is still plugged in
+func Connected(v uint32) bool {
+	joyStickStateLock.RLock()
+	_, ok := joyStickStates[v]
+	joyStickStateLock.RUnlock()
+	return ok
+}
+
 // SetStickState safely sets the current state of the requested joystick
 func SetStickState(k uint32, v joystick.State) {
 	joyStickStateLock.Lock()
@@ -50,11 +58,14 @@ func (h *handler) Trigger(ev string, state interface{}) {
 	if ev == joystick.Disconnected {
 		id, ok := state.(uint32)
 		if ok {
-			SetStickState(id, joystick.State{})
+			joyStickStateLock.Lock()
+			delete(joyStickStates, id)
+			joyStickStateLock.Unlock()
 		}
 		return
 	}
-	if strings.HasSuffix(ev, joystick.ButtonUp) {
+	// Presses are passed on too, so buttons can be held
+	if strings.HasSuffix(ev, joystick.ButtonUp) || strings.HasSuffix(ev, joystick.ButtonDown) {
 		event.Trigger(ev, state)
 	}
 	st, ok := state.(*joystick.State)
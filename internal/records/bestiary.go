@@ -0,0 +1,85 @@
+package records
+
+import "sort"
+
+// A Beast is what is known about a kind of enemy
+type Beast struct {
+	Kills        int64 `json:"kills"`
+	DeathsCaused int64 `json:"deathsCaused"`
+	DeepestSeen  int64 `json:"deepestSeen"`
+	// Variants and Affixes are the names of those seen on the kind of enemy
+	Variants []string `json:"variants"`
+	Affixes  []string `json:"affixes"`
+}
+
+// A Bestiary holds what is known about each kind of enemy, by name.
+// Kinds that have never been seen aren't in it.
+type Bestiary map[string]*Beast
+
+// beast returns the entry for a kind of enemy, adding it if it isn't there
+func (b *Bestiary) beast(name string) *Beast {
+	if *b == nil {
+		*b = Bestiary{}
+	}
+	bst, ok := (*b)[name]
+	if !ok {
+		bst = &Beast{}
+		(*b)[name] = bst
+	}
+	return bst
+}
+
+// Saw an enemy of a kind and variant with some affixes, at some depth
+func (b *Bestiary) Saw(name, variant string, affixes []string, depth int64) {
+	bst := b.beast(name)
+	if depth > bst.DeepestSeen {
+		bst.DeepestSeen = depth
+	}
+	bst.Variants = addName(bst.Variants, variant)
+	for _, a := range affixes {
+		bst.Affixes = addName(bst.Affixes, a)
+	}
+}
+
+// Killed an enemy of a kind
+func (b *Bestiary) Killed(name string) {
+	b.beast(name).Kills++
+}
+
+// Caused is called when an enemy of a kind kills a member of the party
+func (b *Bestiary) Caused(name string) {
+	b.beast(name).DeathsCaused++
+}
+
+// Merge what was learned on a run into the bestiary
+func (b *Bestiary) Merge(run Bestiary) {
+	for name, rb := range run {
+		bst := b.beast(name)
+		bst.Kills += rb.Kills
+		bst.DeathsCaused += rb.DeathsCaused
+		if rb.DeepestSeen > bst.DeepestSeen {
+			bst.DeepestSeen = rb.DeepestSeen
+		}
+		for _, v := range rb.Variants {
+			bst.Variants = addName(bst.Variants, v)
+		}
+		for _, a := range rb.Affixes {
+			bst.Affixes = addName(bst.Affixes, a)
+		}
+	}
+}
+
+// addName to a sorted list of names if it isn't already in it
+func addName(names []string, name string) []string {
+	if name == "" {
+		return names
+	}
+	i := sort.SearchStrings(names, name)
+	if i < len(names) && names[i] == name {
+		return names
+	}
+	names = append(names, "")
+	copy(names[i+1:], names[i:])
+	names[i] = name
+	return names
+}
@@ -8,4 +8,9 @@ type RunInfo struct {
 	Party           *players.Party
 	SectionsCleared int   `json:"SectionsCleared"`
 	EnemiesDefeated int64 `json:"enemiesDefeated"`
+	Chaos           bool  `json:"chaos"`
+	// ChaosSeed made the party of a chaos run
+	ChaosSeed int64 `json:"chaosSeed,omitempty"`
+	// Bestiary is what was learned about enemies during the run
+	Bestiary Bestiary `json:"-"`
 }
@@ -0,0 +1,35 @@
+package records
+
+import (
+	"github.com/oakmound/weekly87/internal/characters/players"
+)
+
+// PassiveFor returns the passive chosen for a class, or the class's default
+func (r *Records) PassiveFor(class int) string {
+	allowed := players.ClassPassives(class)
+	if len(allowed) == 0 {
+		return ""
+	}
+	chosen := r.Passives[class]
+	for _, name := range allowed {
+		if name == chosen {
+			return chosen
+		}
+	}
+	return allowed[0]
+}
+
+// ChoosePassive sets the passive for a class, returning whether the class can choose it
+func (r *Records) ChoosePassive(class int, name string) bool {
+	for _, allowed := range players.ClassPassives(class) {
+		if allowed != name {
+			continue
+		}
+		if r.Passives == nil {
+			r.Passives = make(map[int]string)
+		}
+		r.Passives[class] = name
+		return true
+	}
+	return false
+}
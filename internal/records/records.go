@@ -27,6 +27,26 @@ type Records struct {
 	PartyComp              []players.PartyMember `json:"partyComp"`
 	Deaths                 int                   `json:"deaths"`
 	Wealth                 int                   `json:"wealth"`
+	// Formation is the shape and marching order of PartyComp
+	Formation players.Formation `json:"formation"`
+
+	// ChaosMode makes the next run randomize the party's models, colors and abilities
+	ChaosMode               bool  `json:"chaosMode"`
+	ChaosRuns               int64 `json:"chaosRuns"`
+	FarthestChaosInSections int64 `json:"farthestChaosInSections"`
+
+	// Roster is every living adventurer who has been hired, Memorial every one who has died
+	Roster           []players.Adventurer `json:"roster"`
+	Memorial         []Fallen             `json:"memorial"`
+	NextAdventurerID int64                `json:"nextAdventurerID"`
+
+	// Upgrades are the ability upgrades bought for each class, WealthSpent what they cost
+	Upgrades    map[int][]string `json:"upgrades"`
+	WealthSpent int              `json:"wealthSpent"`
+	// Passives are the passive chosen for each class
+	Passives map[int]string `json:"passives"`
+	// Bestiary fills in as kinds of enemies are seen and killed
+	Bestiary Bestiary `json:"bestiary"`
 
 	LastRun RunInfo `json:"lastRun"`
 }
@@ -61,6 +81,7 @@ func Load() *Records {
 		r.BaseSeed = rand.Int63()
 		r.PartyComp = []players.PartyMember{{PlayerClass: players.Swordsman, AccruedValue: 0, Name: "Dan the Default"}}
 		r.LastRun = RunInfo{EnemiesDefeated: 0, SectionsCleared: 0}
+		r.ensureRoster()
 		data, err := json.Marshal(r)
 		dlog.ErrorCheck(err)
 		_, err = f.Write(data)
@@ -71,6 +92,7 @@ func Load() *Records {
 		if r.PartyComp == nil {
 			r.PartyComp = []players.PartyMember{{PlayerClass: players.Swordsman, AccruedValue: 0, Name: "Dan the Default"}}
 		}
+		r.ensureRoster()
 	}
 	if f != nil {
 		dlog.ErrorCheck(f.Close())
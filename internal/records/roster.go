@@ -0,0 +1,138 @@
+package records
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/oakmound/weekly87/internal/characters/players"
+)
+
+// Fallen is an adventurer who died on a run, remembered in the graveyard
+type Fallen struct {
+	players.Adventurer
+	// DiedIn is how many sections the fatal run cleared
+	DiedIn int       `json:"diedIn"`
+	DiedAt time.Time `json:"diedAt"`
+	// KilledBy names the enemy that killed them, if it is known
+	KilledBy string `json:"killedBy"`
+}
+
+// takenNames are all names used by living or dead adventurers
+func (r *Records) takenNames() map[string]bool {
+	taken := make(map[string]bool, len(r.Roster)+len(r.Memorial))
+	for _, a := range r.Roster {
+		taken[a.Name] = true
+	}
+	for _, f := range r.Memorial {
+		taken[f.Name] = true
+	}
+	return taken
+}
+
+func (r *Records) newAdventurer(class int) players.Adventurer {
+	r.NextAdventurerID++
+	a := players.GenerateAdventurer(rand.New(rand.NewSource(time.Now().UnixNano())),
+		r.NextAdventurerID, class, r.takenNames())
+	r.Roster = append(r.Roster, a)
+	return a
+}
+
+// Adventurer finds the roster entry for the adventurer with the given id
+func (r *Records) Adventurer(id int64) (*players.Adventurer, bool) {
+	for i := range r.Roster {
+		if r.Roster[i].ID == id {
+			return &r.Roster[i], true
+		}
+	}
+	return nil, false
+}
+
+func (r *Records) inParty(id int64) bool {
+	for _, m := range r.PartyComp {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Free lists the adventurers of the given class on the roster who aren't in the party
+func (r *Records) Free(class int) []players.Adventurer {
+	free := []players.Adventurer{}
+	for _, a := range r.Roster {
+		if a.Class == class && !r.inParty(a.ID) {
+			free = append(free, a)
+		}
+	}
+	return free
+}
+
+// HireNew generates a new adventurer of the given class and puts them on the roster
+func (r *Records) HireNew(class int) players.PartyMember {
+	return r.newAdventurer(class).Member()
+}
+
+// Hire an adventurer of the given class who isn't already in the party,
+// taking someone off the roster if they are free or else generating someone new
+func (r *Records) Hire(class int) players.PartyMember {
+	if free := r.Free(class); len(free) != 0 {
+		return free[0].Member()
+	}
+	return r.HireNew(class)
+}
+
+// Survived credits an adventurer with making it home with some chests
+func (r *Records) Survived(id int64, chests int) {
+	a, ok := r.Adventurer(id)
+	if !ok {
+		return
+	}
+	a.RunsSurvived++
+	a.ChestsCarried += chests
+}
+
+// Bury removes an adventurer from the roster and party for good and adds them to the memorial
+func (r *Records) Bury(id int64, sections int, killedBy string) {
+	for i, a := range r.Roster {
+		if a.ID != id {
+			continue
+		}
+		r.Memorial = append(r.Memorial, Fallen{Adventurer: a, DiedIn: sections, DiedAt: time.Now(), KilledBy: killedBy})
+		r.Roster = append(r.Roster[:i], r.Roster[i+1:]...)
+		break
+	}
+	kept := r.PartyComp[:0]
+	for _, m := range r.PartyComp {
+		if m.ID != id {
+			kept = append(kept, m)
+		}
+	}
+	r.PartyComp = kept
+	if len(r.PartyComp) == 0 {
+		// Someone has to lead the next run
+		r.PartyComp = []players.PartyMember{r.Hire(players.Swordsman)}
+	}
+}
+
+// ensureRoster makes sure every member of the party is an adventurer on the roster,
+// for saves from before there was a roster
+func (r *Records) ensureRoster() {
+	for i, m := range r.PartyComp {
+		if m.PlayerClass == players.Empty {
+			continue
+		}
+		if _, ok := r.Adventurer(m.ID); ok && m.ID != 0 {
+			continue
+		}
+		a := r.newAdventurer(m.PlayerClass)
+		if m.Name != "" && !r.takenNames()[m.Name] {
+			// Keep their old name if no one else has it
+			r.Roster[len(r.Roster)-1].Name = m.Name
+			a.Name = m.Name
+		}
+		mem := a.Member()
+		// What they were owed before there was a roster stays with them
+		mem.AccruedValue = m.AccruedValue
+		r.PartyComp[i] = mem
+	}
+}
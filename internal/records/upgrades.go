@@ -0,0 +1,57 @@
+package records
+
+import (
+	"strings"
+
+	"github.com/oakmound/weekly87/internal/abilities"
+)
+
+// HasUpgrade reports whether an upgrade has been bought for a class
+func (r *Records) HasUpgrade(class int, id string) bool {
+	for _, bought := range r.Upgrades[class] {
+		if bought == id {
+			return true
+		}
+	}
+	return false
+}
+
+// UpgradesFor lists the upgrades bought by any class for the given abilities
+func (r *Records) UpgradesFor(abs ...abilities.Ability) []string {
+	ups := []string{}
+	seen := map[string]bool{}
+	for _, a := range abs {
+		prefix := abilities.NameOf(a) + "."
+		for _, bought := range r.Upgrades {
+			for _, id := range bought {
+				if strings.HasPrefix(id, prefix) && !seen[id] {
+					seen[id] = true
+					ups = append(ups, id)
+				}
+			}
+		}
+	}
+	return ups
+}
+
+// CanUpgrade reports whether an upgrade is affordable and unlocked for a class
+func (r *Records) CanUpgrade(class int, up abilities.Upgrade) bool {
+	if r.HasUpgrade(class, up.ID) || up.Cost > r.Wealth {
+		return false
+	}
+	return up.Requires == "" || r.HasUpgrade(class, up.Requires)
+}
+
+// BuyUpgrade spends Wealth on an upgrade for a class, returning whether it was bought
+func (r *Records) BuyUpgrade(class int, up abilities.Upgrade) bool {
+	if !r.CanUpgrade(class, up) {
+		return false
+	}
+	if r.Upgrades == nil {
+		r.Upgrades = make(map[int][]string)
+	}
+	r.Upgrades[class] = append(r.Upgrades[class], up.ID)
+	r.Wealth -= up.Cost
+	r.WealthSpent += up.Cost
+	return true
+}
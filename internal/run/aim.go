@@ -0,0 +1,225 @@
+package run
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak"
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/joystick"
+	"github.com/oakmound/oak/mouse"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/joys"
+	"github.com/oakmound/weekly87/internal/layer"
+)
+
+const (
+	// quickPress is how long an ability can be held and still fire straight ahead
+	quickPress = 200 * time.Millisecond
+	// reticleSpeed is how far the reticle moves each frame with the stick all the way over
+	reticleSpeed = 8.0
+	reticleSize  = 16
+)
+
+var reticleColor = color.RGBA{255, 240, 120, 220}
+
+// An aimer lets an ability with a range be held to aim it at a reticle and released to fire.
+// The reticle follows the mouse, or the right stick of a joystick.
+type aimer struct {
+	sync.Mutex
+	a abilities.Ability
+	p *players.Player
+	// stick is set when the reticle is moved by the right stick of joystick joyID
+	stick bool
+	joyID uint32
+
+	holding   bool
+	pressedAt time.Time
+	// offset of the reticle from the player, for the stick
+	offset  floatgeom.Point2
+	target  floatgeom.Point2
+	reticle *render.CompositeM
+}
+
+func newAimer(a abilities.Ability, p *players.Player) *aimer {
+	am := &aimer{a: a, p: p}
+	event.GlobalBind(am.update, "EnterFrame")
+	return am
+}
+
+func (am *aimer) aimable() bool {
+	return am.a != nil && am.a.Range() > 0
+}
+
+// press starts aiming with the mouse, or fires right away if the ability can't be aimed
+func (am *aimer) press() {
+	am.start(false, 0)
+}
+
+// pressStick starts aiming with the right stick of a joystick
+func (am *aimer) pressStick(joyID uint32) {
+	am.start(true, joyID)
+}
+
+func (am *aimer) start(stick bool, joyID uint32) {
+	if am.a == nil {
+		return
+	}
+	if !am.aimable() {
+		am.a.Trigger()
+		return
+	}
+	am.Lock()
+	defer am.Unlock()
+	if am.holding {
+		return
+	}
+	am.holding = true
+	am.stick = stick
+	am.joyID = joyID
+	am.pressedAt = time.Now()
+	// Start the reticle out ahead of the player
+	dx := am.a.Range() / 2
+	if am.p.Direction() == "LT" {
+		dx *= -1
+	}
+	am.offset = floatgeom.Point2{dx, 0}
+}
+
+// release fires the ability at the reticle, or straight ahead if it was only tapped
+func (am *aimer) release() {
+	am.Lock()
+	if !am.holding {
+		am.Unlock()
+		return
+	}
+	am.holding = false
+	quick := time.Since(am.pressedAt) < quickPress
+	target := am.target
+	if am.reticle != nil {
+		am.reticle.Undraw()
+		am.reticle = nil
+	}
+	am.Unlock()
+
+	if quick {
+		am.a.Trigger()
+		return
+	}
+	am.a.TriggerAt(target)
+}
+
+func (am *aimer) center() floatgeom.Point2 {
+	return floatgeom.Point2{am.p.X() + am.p.W/2, am.p.Y() + am.p.H/2}
+}
+
+func (am *aimer) update(int, interface{}) int {
+	am.Lock()
+	defer am.Unlock()
+	if !am.holding || time.Since(am.pressedAt) < quickPress {
+		return 0
+	}
+	if !am.p.Alive {
+		am.holding = false
+	}
+	c := am.center()
+	if am.stick {
+		js := joys.StickState(am.joyID)
+		// Up on the stick is positive
+		am.offset = floatgeom.Point2{
+			am.offset.X() + float64(js.StickRX)/32000*reticleSpeed,
+			am.offset.Y() - float64(js.StickRY)/32000*reticleSpeed,
+		}
+	} else {
+		me := mouse.LastEvent
+		am.offset = floatgeom.Point2{
+			float64(me.X()) + float64(oak.ViewPos.X) - c.X(),
+			float64(me.Y()) + float64(oak.ViewPos.Y) - c.Y(),
+		}
+	}
+	// Keep the reticle in range
+	if d, rng := math.Hypot(am.offset.X(), am.offset.Y()), am.a.Range(); d > rng {
+		am.offset = floatgeom.Point2{am.offset.X() * rng / d, am.offset.Y() * rng / d}
+	}
+	am.target = floatgeom.Point2{c.X() + am.offset.X(), c.Y() + am.offset.Y()}
+
+	if !am.holding {
+		if am.reticle != nil {
+			am.reticle.Undraw()
+			am.reticle = nil
+		}
+		return 0
+	}
+	if am.reticle == nil {
+		am.reticle = newReticle()
+		render.Draw(am.reticle, layer.Effect)
+	}
+	am.reticle.SetPos(am.target.X()-reticleSize/2, am.target.Y()-reticleSize/2)
+	return 0
+}
+
+// newReticle is a crosshair
+func newReticle() *render.CompositeM {
+	h := render.NewColorBox(reticleSize, 2, reticleColor)
+	h.SetPos(0, reticleSize/2-1)
+	v := render.NewColorBox(2, reticleSize, reticleColor)
+	v.SetPos(reticleSize/2-1, 0)
+	return render.NewCompositeM(h, v)
+}
+
+// bindJoystickAim lets a joystick button hold and release either of a party member's abilities,
+// the second while the right trigger is held. Abilities that can't be aimed fire on release.
+// If joyID is set only that joystick's button counts.
+func bindJoystickAim(button string, first, second *aimer, joyID *uint32) {
+	var lock sync.Mutex
+	var held *aimer
+	pick := func(jState *joystick.State) *aimer {
+		if jState.TriggerR > 100 {
+			return second
+		}
+		return first
+	}
+	state := func(data interface{}) (*joystick.State, bool) {
+		jState, ok := data.(*joystick.State)
+		if !ok || (joyID != nil && jState.ID != *joyID) {
+			return nil, false
+		}
+		return jState, true
+	}
+	event.GlobalBind(func(_ int, data interface{}) int {
+		jState, ok := state(data)
+		if !ok {
+			return 0
+		}
+		if am := pick(jState); am.aimable() {
+			lock.Lock()
+			held = am
+			lock.Unlock()
+			am.pressStick(jState.ID)
+		}
+		return 0
+	}, button+joystick.ButtonDown)
+	event.GlobalBind(func(_ int, data interface{}) int {
+		jState, ok := state(data)
+		if !ok {
+			return 0
+		}
+		lock.Lock()
+		am := held
+		held = nil
+		lock.Unlock()
+		if am != nil {
+			am.release()
+			return 0
+		}
+		if a := pick(jState).a; a != nil {
+			a.Trigger()
+		}
+		return 0
+	}, button+joystick.ButtonUp)
+}
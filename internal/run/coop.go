@@ -0,0 +1,34 @@
+package run
+
+import (
+	"strings"
+
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/key"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/coop"
+)
+
+// bindSeat lets a local player trigger the abilities of the party member they control
+func bindSeat(st coop.Seat, p *players.Player) {
+	aim1, aim2 := newAimer(p.Special1, p), newAimer(p.Special2, p)
+
+	if st.Kind == coop.Joystick {
+		joyID := st.JoyID
+		bindJoystickAim("A", aim1, aim1, &joyID)
+		bindJoystickAim("B", aim2, aim2, &joyID)
+		return
+	}
+	k1, k2 := st.AbilityKeys()
+	for k, am := range map[string]*aimer{k1: aim1, k2: aim2} {
+		am := am
+		event.GlobalBind(func(int, interface{}) int {
+			am.press()
+			return 0
+		}, k)
+		event.GlobalBind(func(int, interface{}) int {
+			am.release()
+			return 0
+		}, key.Up+strings.TrimPrefix(k, key.Down))
+	}
+}
@@ -0,0 +1,76 @@
+package run
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/run/section"
+	"github.com/oakmound/weekly87/internal/timescale"
+)
+
+// directorInterval is how often the director looks over the party
+const directorInterval = 500 * time.Millisecond
+
+// bindDirector keeps the director watching the party. It returns an overlay
+// showing the director's tension, which is only drawn while debugging.
+func bindDirector(pty *players.Party, dir *section.Director) *render.Text {
+	var (
+		lock    sync.Mutex
+		lastHit = timescale.Now()
+		deaths  int
+		next    time.Time
+	)
+	event.GlobalBind(func(int, interface{}) int {
+		lock.Lock()
+		lastHit = timescale.Now()
+		lock.Unlock()
+		return 0
+	}, "PlayerHit")
+	event.GlobalBind(func(int, interface{}) int {
+		lock.Lock()
+		deaths++
+		lock.Unlock()
+		return 0
+	}, "PlayerDeath")
+
+	fnt := render.DefFontGenerator.Copy()
+	fnt.Color = render.FontColor("White")
+	fnt.Size = 14
+	overlay := fnt.Generate().NewStrText("", 20, 250)
+
+	event.GlobalBind(func(int, interface{}) int {
+		now := timescale.Now()
+		if now.Before(next) {
+			return 0
+		}
+		next = now.Add(directorInterval)
+
+		alive := 0
+		carried := int64(0)
+		for _, ply := range pty.Players {
+			if ply.Alive {
+				alive++
+			}
+			for _, v := range ply.ChestValues {
+				carried += v
+			}
+		}
+		lock.Lock()
+		p := section.Pressure{
+			Health:   float64(alive) / float64(len(pty.Players)),
+			Deaths:   deaths,
+			SinceHit: now.Sub(lastHit),
+			Carried:  carried,
+		}
+		lock.Unlock()
+		dir.Observe(p)
+		overlay.SetString(fmt.Sprintf("Tension: %.2f (health %.2f, deaths %d, calm %ds, carrying %d)",
+			dir.Tension(), p.Health, p.Deaths, int(p.SinceHit.Seconds()), p.Carried))
+		return 0
+	}, "EnterFrame")
+	return overlay
+}
@@ -3,6 +3,7 @@ package run
 import (
 	"fmt"
 	"image/color"
+	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
@@ -10,11 +11,14 @@ import (
 
 	klg "github.com/200sc/klangsynthese/audio"
 
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/abilities/buff"
 	"github.com/oakmound/weekly87/internal/characters"
 	"github.com/oakmound/weekly87/internal/characters/doodads"
 	"github.com/oakmound/weekly87/internal/characters/enemies"
 	"github.com/oakmound/weekly87/internal/characters/labels"
 	"github.com/oakmound/weekly87/internal/characters/players"
+	"github.com/oakmound/weekly87/internal/coop"
 	"github.com/oakmound/weekly87/internal/dtools"
 	"github.com/oakmound/weekly87/internal/joys"
 	"github.com/oakmound/weekly87/internal/layer"
@@ -23,12 +27,16 @@ import (
 	"github.com/oakmound/weekly87/internal/records"
 	"github.com/oakmound/weekly87/internal/restrictor"
 	"github.com/oakmound/weekly87/internal/run/section"
+	"github.com/oakmound/weekly87/internal/sfx"
+	"github.com/oakmound/weekly87/internal/timescale"
+	"github.com/oakmound/weekly87/internal/vfx"
 
 	"github.com/oakmound/oak"
 	"github.com/oakmound/oak/alg/floatgeom"
 	"github.com/oakmound/oak/collision"
 	"github.com/oakmound/oak/dlog"
 	"github.com/oakmound/oak/entities/x/btn"
+	"github.com/oakmound/oak/entities/x/move"
 	"github.com/oakmound/oak/event"
 	"github.com/oakmound/oak/joystick"
 	"github.com/oakmound/oak/key"
@@ -67,9 +75,31 @@ var Scene = scene.Scene{
 
 		restrictor.ResetDefault()
 		restrictor.Start(1)
+		timescale.Start()
 
+		abilities.ResetGroups()
+		rec := records.Load()
 		ptycon := players.PartyConstructor{
-			Players: players.ClassConstructor(records.Load().PartyComp),
+			Players:   players.ClassConstructor(rec.PartyComp),
+			Formation: rec.Formation,
+		}
+		var chaosSeed int64
+		if rec.ChaosMode {
+			// Every chaos run gets a party of its own. The seed is kept with the run,
+			// so the party can be reported and made again.
+			chaosSeed = rand.Int63()
+			ptycon.Players = players.ChaosConstructor(rec.PartyComp, chaosSeed)
+		}
+		for i := range ptycon.Players {
+			cons := &ptycon.Players[i]
+			cons.Upgrades = rec.Upgrades[cons.Class]
+			if rec.ChaosMode {
+				// Chaos members don't have the abilities of the class they were hired as
+				cons.Upgrades = rec.UpgradesFor(cons.Special1, cons.Special2)
+			}
+			if name := rec.PassiveFor(cons.Class); name != "" {
+				cons.Passive = abilities.PassiveNamed(name)
+			}
 		}
 		ptycon.Players[0].Position = floatgeom.Point2{players.WallOffset, float64(oak.ScreenHeight / 2)}
 		pty, err := ptycon.NewRunningParty()
@@ -79,9 +109,47 @@ var Scene = scene.Scene{
 			Party:           pty,
 			SectionsCleared: 1,
 			EnemiesDefeated: 0,
+			Chaos:           rec.ChaosMode,
+			ChaosSeed:       chaosSeed,
 		}
 
-		if oak.MostRecentInput == oak.Joystick {
+		// Ability icon layout
+		const aRendDims = 64.0
+		const aPad = aRendDims + 12.0 //Size of ability image plus padding
+		const cornerPad = 20
+		// Passive icons sit under the ability icons
+		const passiveH = abilities.PassiveIconSize + 4
+
+		seats := pty.Seats()
+		coopActive := len(seats) > 1
+		if coopActive {
+			// Each local player gets their own highlight over the party member they control
+			for si, st := range seats {
+				if si >= len(pty.Players) {
+					break
+				}
+				bindSeat(st, pty.Players[si])
+				seatHighlight := render.NewColorBox(int(aPad), int(aPad*2+passiveH), coop.Color(si))
+				seatHighlight.SetPos(float64(cornerPad/2)+float64(si)*aPad, cornerPad/2)
+				render.Draw(seatHighlight, layer.UI, 10)
+			}
+			if coop.TakeTurns {
+				fnt := render.DefFontGenerator.Copy()
+				fnt.Size = 14
+				steerText := fnt.Generate().NewStrText("P1 steering", float64(cornerPad), cornerPad+aPad*2+passiveH+4)
+				render.Draw(steerText, layer.UI, 11)
+				event.GlobalBind(func(_ int, data interface{}) int {
+					steerer, ok := data.(int)
+					if !ok {
+						dlog.Error("SteererChanged sent a non-int")
+						return 0
+					}
+					steerText.SetString("P" + strconv.Itoa(steerer+1) + " steering")
+					steerText.SetX(float64(cornerPad) + float64(steerer)*aPad)
+					return 0
+				}, "SteererChanged")
+			}
+		} else if oak.MostRecentInput == oak.Joystick {
 			joyID := joys.LowestID()
 			fmt.Println("Ability Highlgihit")
 			abilityHighlight := render.NewHorizontalGradientBox(100, 68, color.RGBA{80, 64, 34, 128}, color.RGBA{0, 0, 0, 0})
@@ -98,7 +166,24 @@ var Scene = scene.Scene{
 			}, "EnterFrame")
 		}
 
+		// Formation controls: rotate who leads, or change the party's shape.
+		// These sit between the two keyboard seats so neither presses them by accident.
+		rotateOrder := func(int, interface{}) int {
+			pty.RotateOrder()
+			return 0
+		}
+		event.GlobalBind(rotateOrder, key.Down+key.H)
+		event.GlobalBind(rotateOrder, "LeftShoulder"+joystick.ButtonUp)
+		nextShape := func(int, interface{}) int {
+			pty.SetShape(pty.Formation.Shape.Next())
+			return 0
+		}
+		event.GlobalBind(nextShape, key.Down+key.N)
+		event.GlobalBind(nextShape, "RightShoulder"+joystick.ButtonUp)
+
 		tracker := section.NewTracker(BaseSeed)
+		directorOverlay := bindDirector(pty, tracker.Director())
+		directorShowing := false
 
 		for i, p := range pty.Players {
 			render.Draw(p.R, layer.Play, 2)
@@ -115,13 +200,12 @@ var Scene = scene.Scene{
 			})
 
 			// Ability icon rendering / binding
-			const aRendDims = 64.0
-			const aPad = aRendDims + 12.0 //Size of ability image plus padding
-			const cornerPad = 20
 			abilityKeys := []string{
 				"Q", "W", "E", "R", "T",
 			}
 			abilityX := float64(cornerPad + i*aPad)
+			// In co-op, seated party members only answer to their own seat's keys
+			sharedKeys := i < 10 && (!coopActive || i >= len(seats))
 
 			btnOpts := btn.And(menus.BtnCfgB, btn.Layers(layer.UI, 0),
 				btn.Pos(abilityX, cornerPad),
@@ -148,25 +232,18 @@ var Scene = scene.Scene{
 						trg()
 						return 0
 					}))
-				keyToBind := key.Down + strconv.Itoa(i+1)
-				if i < 10 {
-					// Todo joystick triggers
-					btnOpts = btn.And(btnOpts, btn.Binding(keyToBind, func(int, interface{}) int {
-						trg()
-						return 0
-					}))
-				}
-
-				if i < 4 {
-					btnOpts = btn.And(btnOpts, btn.Binding(joyBtns[i]+joystick.ButtonUp, func(_ int, state interface{}) int {
-						jState := state.(*joystick.State)
-						fmt.Println("Joystick TriggerR", jState.TriggerR)
-						if jState.TriggerR < 100 {
-							fmt.Println("Triggering ability 1")
-							trg()
-						}
-						return 0
-					}))
+				if sharedKeys {
+					// Holding the key aims abilities that can be aimed
+					am := newAimer(p.Special1, p)
+					btnOpts = btn.And(btnOpts,
+						btn.Binding(key.Down+strconv.Itoa(i+1), func(int, interface{}) int {
+							am.press()
+							return 0
+						}),
+						btn.Binding(key.Up+strconv.Itoa(i+1), func(int, interface{}) int {
+							am.release()
+							return 0
+						}))
 				}
 				newBtn := btn.New(btnOpts)
 				p.Special1.SetButton(newBtn)
@@ -183,28 +260,33 @@ var Scene = scene.Scene{
 						return 0
 					}))
 
-				if i < 10 {
-					btnOpts = btn.And(btnOpts, btn.Binding(key.Down+abilityKeys[i], func(int, interface{}) int {
-						trg()
-						return 0
-					}))
-				}
-				if i < 4 {
-					btnOpts = btn.And(btnOpts, btn.Binding(joyBtns[i]+joystick.ButtonUp, func(_ int, state interface{}) int {
-						jState := state.(*joystick.State)
-						fmt.Println("Joystick TriggerR", jState.TriggerR)
-						if jState.TriggerR > 100 {
-							fmt.Println("Triggering ability 1")
-							trg()
-						}
-						return 0
-					}))
+				if sharedKeys {
+					am := newAimer(p.Special2, p)
+					btnOpts = btn.And(btnOpts,
+						btn.Binding(key.Down+abilityKeys[i], func(int, interface{}) int {
+							am.press()
+							return 0
+						}),
+						btn.Binding(key.Up+abilityKeys[i], func(int, interface{}) int {
+							am.release()
+							return 0
+						}))
 				}
 
 				newBtn := btn.New(btnOpts)
 				p.Special2.SetButton(newBtn)
 
 			}
+			if p.Passive != nil {
+				icon := p.Passive.Icon.Copy()
+				icon.SetPos(abilityX, cornerPad+aPad*2)
+				render.Draw(icon, layer.UI, 1)
+			}
+
+			// In co-op each joystick only controls its own party member
+			if i < 4 && !coopActive {
+				bindJoystickAim(joyBtns[i], newAimer(p.Special1, p), newAimer(p.Special2, p), nil)
+			}
 		}
 
 		sec1 := tracker.Next()
@@ -226,6 +308,9 @@ var Scene = scene.Scene{
 		)
 
 		var lastX float64
+		// carryPursuers is set once sectionAt is
+		var carryPursuers func([]characters.Character)
+		shots := &enemyShots{}
 
 		// Create a debug for Section drawing
 		secDebugHeight := 20
@@ -276,6 +361,7 @@ var Scene = scene.Scene{
 
 						pty.ShiftX(-sec1.W() * 2)
 						sec3.ShiftEntities(-sec1.W() * 2)
+						shots.shift(-sec1.W() * 2)
 						oak.ShiftScreen(-int(sec1.W())*2, 0)
 
 						go func() {
@@ -296,6 +382,7 @@ var Scene = scene.Scene{
 						go func() {
 							// - A-=2
 							// - C-=2
+							pursuers := append(sec1.TakePursuers(), sec3.TakePursuers()...)
 							sec1.Destroy()
 							sec3.Destroy()
 
@@ -306,6 +393,7 @@ var Scene = scene.Scene{
 							sec3.Draw()
 							sec1.Draw()
 							sec1.ActivateEntities()
+							carryPursuers(pursuers)
 
 							if tracker.AtStart() {
 								oak.SetViewportBounds(0, 0, 8000, 8000)
@@ -316,6 +404,8 @@ var Scene = scene.Scene{
 					}
 				} else if lastX >= sec1Mid {
 					if x < sec1Mid && !tracker.AtStart() {
+						// Pursuers keep up with the party as it is teleported
+						pursuers := append(sec2.TakePursuers(), sec3.TakePursuers()...)
 						go func() {
 							// - Teleport all entities two section widths forward (Including the viewport)
 							// - B-=2
@@ -324,6 +414,7 @@ var Scene = scene.Scene{
 							sec2.SetBackgroundX(sec1.W())
 							sec2.Draw()
 							sec2.ActivateEntities()
+							carryPursuers(pursuers)
 
 							pty.SpeedUp(1)
 							runInfo.SectionsCleared++
@@ -331,6 +422,10 @@ var Scene = scene.Scene{
 
 						pty.ShiftX(sec1.W() * 2)
 						sec1.ShiftEntities(sec1.W() * 2)
+						shots.shift(sec1.W() * 2)
+						for _, e := range pursuers {
+							move.ShiftX(e, sec1.W()*2)
+						}
 
 						oak.ShiftScreen(int(sec1.W())*2, 0)
 
@@ -386,36 +481,164 @@ var Scene = scene.Scene{
 			dlog.Info("An Enemy Died")
 
 			info := data.([]int64)
-			tracker.UpdateHistory(info[0],
-				section.Change{
-					Typ: section.EntityDestroyed,
-					Val: int(info[1])})
+			if info[1] != enemies.Untracked {
+				tracker.UpdateHistory(info[0],
+					section.Change{
+						Typ: section.EntityDestroyed,
+						Val: int(info[1])})
+			}
 			runInfo.EnemiesDefeated += info[0]
 
 			return 0
 		}, "EnemyDeath")
 
+		// sectionAt finds which of the three live sections contains x
+		sectionAt := func(x float64) *section.Section {
+			if x < sec1.W() {
+				return sec1
+			} else if x < 2*sec1.W() {
+				return sec2
+			}
+			return sec3
+		}
+
+		// carryPursuers moves pursuers taken from destroyed sections into the sections they are in now
+		carryPursuers = func(pursuers []characters.Character) {
+			for _, e := range pursuers {
+				sectionAt(e.X()).AppendEntities(e)
+			}
+		}
+
 		event.GlobalBind(func(cid int, data interface{}) int {
 			dlog.Info("A character fired an ability")
 			artifacts := data.([]characters.Character)
 
-			abilitySection := sec3
-			//Add to appropriate section and potentially to the tracker's changelog for the given section
-			if pty.Players[0].X() < (sec1.W()) { //In section 1
-				abilitySection = sec1
-			} else if pty.Players[0].X() < (2 * sec1.W()) { //In section 2
-				abilitySection = sec2
-			}
+			// Add to appropriate section. Artifacts aren't remembered, so they are kept
+			// apart from the entities remembered changes refer to by index.
+			abilitySection := sectionAt(pty.Players[0].X())
 			abilitySection.AppendEntities(artifacts...)
 
-			for _, a := range artifacts {
-				if p, ok := a.(Persistable); ok && p.ShouldPersist() {
-					tracker.UpdateHistory(abilitySection.GetId(), section.Change{})
+			return 0
+		}, "AbilityFired")
+
+		event.GlobalBind(func(cid int, data interface{}) int {
+			shots.add(data.([]characters.Character))
+			return 0
+		}, "EnemyFired")
+
+		event.GlobalBind(func(cid int, data interface{}) int {
+			dc, ok := data.(players.DroppedChest)
+			if !ok {
+				dlog.Error("ChestDropped sent a non-DroppedChest")
+				return 0
+			}
+			dropSection := sectionAt(dc.Pos.X())
+			change := section.Change{
+				Typ: section.ChestDropped,
+				Val: int(dc.Value),
+				Pos: floatgeom.Point2{dc.Pos.X() - dropSection.X(), dc.Pos.Y()},
+			}
+			tracker.ApplyLive(dropSection, change)
+			return 0
+		}, "ChestDropped")
+
+		event.GlobalBind(func(cid int, data interface{}) int {
+			spawned, ok := data.([]*enemies.BasicEnemy)
+			if !ok {
+				dlog.Error("EnemiesSpawned sent a non-enemy list")
+				return 0
+			}
+			// Spawned enemies live in the section they appear in, but aren't remembered,
+			// so like artifacts they are kept apart from the entities changes refer to
+			for _, e := range spawned {
+				e.Activate()
+				render.Draw(e.GetRenderable(), layer.Play, 1)
+				sectionAt(e.X()).AppendEntities(e)
+			}
+			return 0
+		}, "EnemiesSpawned")
+
+		event.GlobalBind(func(cid int, data interface{}) int {
+			info := data.([]int64)
+			tracker.UpdateHistory(info[0],
+				section.Change{
+					Typ: section.EntityDestroyed,
+					Val: int(info[1])})
+			return 0
+		}, "ChestTaken")
+
+		// The bestiary fills in as enemies are seen, killed and kill
+		bestiaryLock := sync.Mutex{}
+		event.GlobalBind(func(cid int, data interface{}) int {
+			s, ok := data.(enemies.Sighting)
+			if !ok {
+				dlog.Error("EnemySeen sent a non-sighting")
+				return 0
+			}
+			bestiaryLock.Lock()
+			runInfo.Bestiary.Saw(s.Type, s.Variant, s.Affixes, s.Depth)
+			bestiaryLock.Unlock()
+			return 0
+		}, "EnemySeen")
+		event.GlobalBind(func(cid int, data interface{}) int {
+			name, ok := data.(string)
+			if !ok {
+				dlog.Error("EnemyKilled sent a non-string")
+				return 0
+			}
+			bestiaryLock.Lock()
+			runInfo.Bestiary.Killed(name)
+			bestiaryLock.Unlock()
+			return 0
+		}, "EnemyKilled")
+		event.GlobalBind(func(cid int, data interface{}) int {
+			by, _ := data.(string)
+			if by == "" {
+				return 0
+			}
+			bestiaryLock.Lock()
+			runInfo.Bestiary.Caused(enemies.BaseName(by))
+			bestiaryLock.Unlock()
+			return 0
+		}, "PlayerDeath")
+
+		event.GlobalBind(func(cid int, data interface{}) int {
+			dropped, ok := data.([]enemies.DroppedLoot)
+			if !ok {
+				dlog.Error("LootDropped sent a non-loot list")
+				return 0
+			}
+			// Loot is remembered, so it is still there when the party runs back
+			for _, dl := range dropped {
+				dropSection := sectionAt(dl.Pos.X())
+				change := section.Change{
+					Typ:  section.LootDropped,
+					Val:  int(dl.Value),
+					Loot: dl.Kind,
+					Pos:  floatgeom.Point2{dl.Pos.X() - dropSection.X(), dl.Pos.Y()},
 				}
+				tracker.ApplyLive(dropSection, change)
 			}
+			return 0
+		}, "LootDropped")
+
+		event.GlobalBind(func(cid int, data interface{}) int {
+			info := data.([]int64)
+			tracker.UpdateHistory(info[0],
+				section.Change{
+					Typ: section.EntityDestroyed,
+					Val: int(info[1])})
+			return 0
+		}, "LootTaken")
 
+		dropChest := func(int, interface{}) int {
+			if !pty.DropChest() {
+				sfx.Play("nope1")
+			}
 			return 0
-		}, "AbilityFired")
+		}
+		event.GlobalBind(dropChest, key.Down+key.G)
+		event.GlobalBind(dropChest, "Back"+joystick.ButtonUp)
 
 		bkgMusic, err = music.Start(true, "run2.wav")
 		dlog.ErrorCheck(err)
@@ -457,11 +680,27 @@ var Scene = scene.Scene{
 			}
 			debugTree.DrawDisabled = true
 		})
+		oak.AddCommand("director", func(args []string) {
+			dlog.Warn("Cheating to toggle the spawn director overlay")
+			if directorShowing {
+				directorOverlay.Undraw()
+			} else {
+				render.Draw(directorOverlay, layer.Debug, 1)
+			}
+			directorShowing = !directorShowing
+		})
 		oak.AddCommand("cooldowns", func(args []string) {
 			dlog.Warn("Cheating to toggle debug mode for cooldowns")
 			pty.Debug = !pty.Debug
 
 		})
+		oak.AddCommand("haste", func(args []string) {
+			dlog.Warn("Cheating to haste the party's cooldowns")
+			hasteIcon := render.NewColorBox(16, 16, color.RGBA{220, 220, 60, 255})
+			for _, ply := range pty.Players {
+				ply.AddBuff(buff.Haste(hasteIcon, 10*time.Second))
+			}
+		})
 		oak.AddCommand("speedup", func(args []string) {
 			up := 5.0
 			if len(args) > 0 {
@@ -541,6 +780,7 @@ var Scene = scene.Scene{
 		(*bkgMusic).Stop()
 		restrictor.Stop()
 		restrictor.Clear()
+		vfx.ResetFloaters()
 		return nextscene, &scene.Result{NextSceneInput: Outcome{runInfo}}
 	},
 }
@@ -1,8 +1,12 @@
 package section
 
 import (
+	"github.com/oakmound/oak/alg/floatgeom"
 	"github.com/oakmound/oak/dlog"
+	"github.com/oakmound/oak/render"
 	"github.com/oakmound/weekly87/internal/characters"
+	"github.com/oakmound/weekly87/internal/characters/doodads"
+	"github.com/oakmound/weekly87/internal/layer"
 )
 
 type ChangeType int
@@ -10,30 +14,93 @@ type ChangeType int
 const (
 	EntityDestroyed ChangeType = iota
 	EntityAdded
+	ChestDropped
+	LootDropped
 )
 
 type Change struct {
 	Typ    ChangeType
 	Val    int
 	Entity characters.Character
+	// Pos is relative to the left of the section
+	Pos floatgeom.Point2
+	// Loot is the kind of loot dropped
+	Loot doodads.LootKind
 }
 
+// ApplyChange to the section. Changes index into the entities of the section's
+// home, which are the same each time the section is generated, along with
+// whatever earlier changes added.
 func (s *Section) ApplyChange(ch Change) {
+	h := s.home()
 	switch ch.Typ {
 	case EntityDestroyed:
 		// val is index of entity destroyed
-		if ch.Val > len(s.entities) {
+		h.entityMutex.Lock()
+		if ch.Val >= len(h.entities) {
+			h.entityMutex.Unlock()
 			dlog.Error("Entity to destroy", ch.Val, "does not exist in section")
 			return
 		}
-		s.entityMutex.Lock()
-		s.entities[ch.Val] = nil
-		s.entityMutex.Unlock()
+		e := h.entities[ch.Val]
+		h.entities[ch.Val] = nil
+		h.entityMutex.Unlock()
+		// The entity was generated again along with the section, so it has to
+		// be destroyed again too, or it would keep acting without being seen
+		if e != nil {
+			e.Destroy()
+		}
 	case EntityAdded:
-		s.entityMutex.Lock()
-		s.entities = append(s.entities, ch.Entity)
-		s.entityMutex.Unlock()
+		h.entityMutex.Lock()
+		h.entities = append(h.entities, ch.Entity)
+		h.entityMutex.Unlock()
+	case ChestDropped:
+		s.addChest(int64(ch.Val), ch.Pos)
+	case LootDropped:
+		// val is the value of the loot dropped
+		if ch.Loot == doodads.SmallChest {
+			s.addChest(int64(ch.Val), ch.Pos)
+			return
+		}
+		l := doodads.NewLoot(ch.Loot, int64(ch.Val))
+		l.SetPos(s.X()+ch.Pos.X(), ch.Pos.Y())
+		h.entityMutex.Lock()
+		l.SectionID = h.id
+		l.Idx = int64(len(h.entities))
+		h.entities = append(h.entities, l)
+		h.entityMutex.Unlock()
 	default:
 		dlog.Error("Unknown section change type:", ch.Typ)
 	}
 }
+
+// addChest of some value to the section, pos being relative to its left
+func (s *Section) addChest(value int64, pos floatgeom.Point2) {
+	h := s.home()
+	c := doodads.NewChest(value)
+	c.SetPos(s.X()+pos.X(), pos.Y())
+	h.entityMutex.Lock()
+	c.SectionID = h.id
+	c.Idx = int64(len(h.entities))
+	h.entities = append(h.entities, c)
+	h.entityMutex.Unlock()
+}
+
+// ApplyLiveChange applies a change to a section that is already on screen,
+// activating anything the change added
+func (s *Section) ApplyLiveChange(ch Change) {
+	h := s.home()
+	h.entityMutex.Lock()
+	before := len(h.entities)
+	h.entityMutex.Unlock()
+	s.ApplyChange(ch)
+	h.entityMutex.Lock()
+	added := h.entities[before:]
+	h.entityMutex.Unlock()
+	for _, e := range added {
+		if e != nil {
+			e.Activate()
+			render.Draw(e.GetRenderable(), layer.Play, 1)
+		}
+	}
+}
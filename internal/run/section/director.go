@@ -0,0 +1,69 @@
+package section
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Pressure is what the director watches to judge how the run is going
+type Pressure struct {
+	// Health is the fraction of the party still alive
+	Health float64
+	// Deaths is how many times party members have died this run
+	Deaths int
+	// SinceHit is how long it has been since the party was last hit
+	SinceHit time.Duration
+	// Carried is the value of the chests the party is carrying
+	Carried int64
+}
+
+const (
+	// calmAfter is how long without being hit before the party counts as calm
+	calmAfter = 20 * time.Second
+	// deathCap is how many deaths it takes to stop adding tension
+	deathCap = 3
+	// carriedWeight is how much each point of carried value eases tension,
+	// so a rich party is pushed harder
+	carriedWeight = .01
+	maxCarried    = .25
+	// ease is how far tension moves toward where it should be on each observation
+	ease = .1
+)
+
+// Bounds are the least and most a plan lets the director scale something by
+type Bounds struct {
+	Min, Max float64
+}
+
+// At scales from Max when the run is calm to Min when it is tense
+func (b Bounds) At(tension float64) float64 {
+	return b.Max - tension*(b.Max-b.Min)
+}
+
+// A Director adjusts how many enemies are spawned and how often they are elites
+// by how tense the run is. It only judges; the tracker's rng still makes every roll.
+type Director struct {
+	sync.Mutex
+	tension float64
+}
+
+// Observe moves the director's tension toward what the pressure calls for
+func (d *Director) Observe(p Pressure) {
+	target := .45 * (1 - p.Health)
+	target += .2 * math.Min(float64(p.Deaths), deathCap) / deathCap
+	target += .35 * (1 - math.Min(float64(p.SinceHit)/float64(calmAfter), 1))
+	target -= math.Min(float64(p.Carried)*carriedWeight, maxCarried)
+	target = math.Max(0, math.Min(1, target))
+
+	d.Lock()
+	d.tension += (target - d.tension) * ease
+	d.Unlock()
+}
+
+// Tension is from 0, a calm run, to 1, a run that is barely holding on
+func (d *Director) Tension() float64 {
+	d.Lock()
+	defer d.Unlock()
+	return d.tension
+}
@@ -17,8 +17,15 @@ type entityPlan struct {
 	chestCount        intrange.Range
 	chestRange        intrange.Range
 	enemyCount        intrange.Range
-	enemyDistribution [enemies.TypeLimit]float64
+	enemyDistribution map[string]float64
 	enemyVariantRange intrange.Range
+	// enemyDensity and eliteOdds bound how far the director can scale
+	// the number of enemies and how often they are elites
+	enemyDensity Bounds
+	eliteOdds    Bounds
+	// packChance is how likely each spawn is to be one of the plan's packs instead
+	packChance float64
+	packs      []enemies.PackPlan
 }
 
 type tilePlan struct {
@@ -63,12 +70,23 @@ func Init() {
 		chestCount: intrange.NewLinear(0, 5),
 		chestRange: intrange.NewLinear(1, 5),
 		enemyCount: intrange.NewLinear(4, 9),
-		enemyDistribution: [...]float64{
-			enemies.Hare:   .5,
-			enemies.Mantis: .5,
-			enemies.Tree:   1,
+		enemyDistribution: map[string]float64{
+			"Hare":    .5,
+			"Mantis":  .5,
+			"Tree":    1,
+			"Spitter": .2,
+			"Lobber":  .2,
+			"Seeker":  .1,
 		},
 		enemyVariantRange: intrange.NewLinear(0, enemies.VariantCount-1),
+		enemyDensity:      Bounds{Min: .6, Max: 1.4},
+		eliteOdds:         Bounds{Min: .5, Max: 1.5},
+		packChance:        .2,
+		packs: []enemies.PackPlan{
+			{Type: "Hare", Min: 4, Max: 7, Formation: enemies.Swarm, Spacing: 30, Surround: true},
+			{Type: "Mantis", Min: 3, Max: 4, Formation: enemies.Line, Spacing: 50},
+			{Type: "Tree", Min: 3, Max: 5, Formation: enemies.Cluster, Spacing: 45},
+		},
 	}
 
 	aPlanWeight := tileWeight{
@@ -16,11 +16,19 @@ var (
 )
 
 type Section struct {
-	id          int64
-	ground      *render.Sprite
-	wall        *render.Sprite
-	entities    []characters.Character
+	id     int64
+	ground *render.Sprite
+	wall   *render.Sprite
+	// entities are those the section was generated with and those added by
+	// remembered changes. Changes refer to them by index, so the list must come
+	// out the same each time the section is regenerated.
+	entities []characters.Character
+	// transient entities are in the section while it is around, but aren't remembered
+	transient   []characters.Character
 	entityMutex sync.Mutex
+	// original is the section this was copied from, which holds the entities
+	// for both, or nil if this is not a copy
+	original *Section
 }
 type MoverWithParticles interface {
 	MoveParticles(floatgeom.Point2)
@@ -32,9 +40,18 @@ func (s *Section) Copy() *Section {
 		ground:      s.ground.Copy().(*render.Sprite),
 		wall:        s.wall.Copy().(*render.Sprite),
 		entityMutex: sync.Mutex{},
+		original:    s.home(),
 	}
 }
 
+// home is the section whose entities changes to this one refer to
+func (s *Section) home() *Section {
+	if s.original != nil {
+		return s.original
+	}
+	return s
+}
+
 func (s *Section) Draw() {
 	render.Draw(s.ground, layer.Ground)
 	render.Draw(s.wall, layer.Background)
@@ -56,11 +73,15 @@ func (s *Section) SetBackgroundX(x float64) {
 func (s *Section) Destroy() {
 	s.ground.Undraw()
 	s.wall.Undraw()
-	for _, e := range s.entities {
-		if e != nil {
-			e.Destroy()
+	s.entityMutex.Lock()
+	for _, list := range [][]characters.Character{s.entities, s.transient} {
+		for _, e := range list {
+			if e != nil {
+				e.Destroy()
+			}
 		}
 	}
+	s.entityMutex.Unlock()
 }
 
 // X returns the leftmost x value of this section
@@ -86,20 +107,55 @@ func (s *Section) ActivateEntities() {
 
 func (s *Section) ShiftEntities(shift float64) {
 	s.entityMutex.Lock()
-	for _, e := range s.entities {
-		if e != nil {
-			move.ShiftX(e, shift)
-			if pm, ok := e.(MoverWithParticles); ok {
-				pm.MoveParticles(floatgeom.Point2{shift, 0})
+	for _, list := range [][]characters.Character{s.entities, s.transient} {
+		for _, e := range list {
+			if e != nil {
+				move.ShiftX(e, shift)
+				if pm, ok := e.(MoverWithParticles); ok {
+					pm.MoveParticles(floatgeom.Point2{shift, 0})
+				}
 			}
 		}
 	}
 	s.entityMutex.Unlock()
 }
 
+// A Pursuer can follow the party out of the section it was made in
+type Pursuer interface {
+	Pursuing() bool
+}
+
+// TakePursuers removes the entities chasing the party from the section,
+// so they live on when it is destroyed
+func (s *Section) TakePursuers() []characters.Character {
+	taken := []characters.Character{}
+	s.entityMutex.Lock()
+	for i, e := range s.entities {
+		if p, ok := e.(Pursuer); ok && p.Pursuing() {
+			taken = append(taken, e)
+			// Leave the slot empty, so changes to the section still find the rest by index
+			s.entities[i] = nil
+		}
+	}
+	kept := s.transient[:0]
+	for _, e := range s.transient {
+		if p, ok := e.(Pursuer); ok && p.Pursuing() {
+			taken = append(taken, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	s.transient = kept
+	s.entityMutex.Unlock()
+	return taken
+}
+
+// AppendEntities adds entities to the section that aren't remembered, like
+// the party's abilities or enemies carried in from elsewhere. They don't change
+// the indices remembered changes use.
 func (s *Section) AppendEntities(e ...characters.Character) {
 	s.entityMutex.Lock()
-	s.entities = append(s.entities, e...)
+	s.transient = append(s.transient, e...)
 	s.entityMutex.Unlock()
 }
 
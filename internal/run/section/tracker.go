@@ -1,7 +1,9 @@
 package section
 
 import (
+	"math"
 	"math/rand"
+	"sync"
 
 	"github.com/oakmound/oak"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/200sc/go-dist/floatrange"
 
 	"github.com/oakmound/oak/alg"
+	"github.com/oakmound/oak/alg/floatgeom"
 
 	"github.com/oakmound/oak/dlog"
 	"github.com/oakmound/oak/render"
@@ -21,7 +24,12 @@ type Tracker struct {
 	sectionsDeep int64
 	rng          *rand.Rand
 	*compressor
-	changes map[int64][]Change
+	changeLock sync.Mutex
+	changes    map[int64][]Change
+	// tensions are what the director's tension was when each depth was first
+	// generated, so it is generated the same way when the party comes back
+	tensions map[int64]float64
+	director *Director
 }
 
 func NewTracker(baseSeed int64) *Tracker {
@@ -30,6 +38,8 @@ func NewTracker(baseSeed int64) *Tracker {
 		rng:        rand.New(rand.NewSource(baseSeed)),
 		compressor: &compressor{},
 		changes:    make(map[int64][]Change),
+		tensions:   make(map[int64]float64),
+		director:   &Director{},
 	}
 }
 
@@ -63,6 +73,11 @@ func (st *Tracker) At() int64 {
 	return st.sectionsDeep
 }
 
+// Director returns what scales the enemies the tracker spawns
+func (st *Tracker) Director() *Director {
+	return st.director
+}
+
 func (st *Tracker) Prev() *Section {
 	return st.Produce(-1)
 }
@@ -130,14 +145,31 @@ func (st *Tracker) Produce(delta int64) *Section {
 		}
 	}
 
+	fieldTop, fieldBottom := float64(oak.ScreenHeight)*1/3, float64(oak.ScreenHeight)-64
 	fieldX := floatrange.NewLinear(0, float64(oak.ScreenWidth))
-	fieldY := floatrange.NewLinear(float64(oak.ScreenHeight)*1/3, float64(oak.ScreenHeight)-64)
+	fieldY := floatrange.NewLinear(fieldTop, fieldBottom)
+	// Positions come from the section's rng, so the same seed places enemies the same way
+	fieldX.SetRand(st.rng)
+	fieldY.SetRand(st.rng)
+
+	typeWeights := make([]float64, enemies.TypeLimit)
+	for typ := range typeWeights {
+		typeWeights[typ] = plan.enemyDistribution[enemies.TypeName(typ)]
+	}
+	enemyDist := alg.RemainingWeights(typeWeights)
 
-	enemyDist := alg.RemainingWeights(plan.enemyDistribution[:])
+	// The director scales how many enemies there are and how often they are elites
+	tension := st.tensionAt(st.sectionsDeep)
+	eliteOdds := plan.eliteOdds.At(tension)
 
 	if !(st.sectionsDeep == 1 && delta > 0) {
-		for i := 0; i < plan.enemyCount.Poll(); i++ {
-			typ := alg.WeightedChooseOneSeeded(enemyDist, st.rng)
+		scaled := float64(plan.enemyCount.Poll()) * plan.enemyDensity.At(tension)
+		enemyCount := int(scaled)
+		if st.rng.Float64() < scaled-float64(enemyCount) {
+			enemyCount++
+		}
+		dlog.Info("Director tension", tension, "spawning", enemyCount)
+		spawn := func(typ int, idx int64) *enemies.BasicEnemy {
 			// section 1 - no variants <.1
 			// section 10 - some variants >.10
 			// section 50 - lots of variants >.50
@@ -149,13 +181,43 @@ func (st *Tracker) Produce(delta int64) *Section {
 				enemyID += plan.enemyVariantRange.Poll()
 			}
 			cs := enemies.Constructors[enemyID]
-			e, err := cs.NewEnemy(st.sectionsDeep, int64(len(st.entities)))
+			e, err := cs.NewEnemy(st.sectionsDeep, idx)
 			if delta < 0 {
 				e.RunBackwards()
 			}
 			dlog.ErrorCheck(err)
+			// Deeper sections have more elites
+			e.AddAffixes(enemies.RollAffixes(st.sectionsDeep, st.rng, cs.Affixes, eliteOdds)...)
+			return e
+		}
+		for spawned := 0; spawned < enemyCount; {
+			// Some enemies come in packs
+			if len(plan.packs) > 0 && st.rng.Float64() < plan.packChance {
+				pp := plan.packs[st.rng.Intn(len(plan.packs))]
+				typ, ok := enemies.TypeNamed(pp.Type)
+				if !ok {
+					dlog.Error("Unknown enemy type in pack", pp.Type)
+					spawned++
+					continue
+				}
+				members := make([]*enemies.BasicEnemy, pp.Size(st.rng))
+				for j := range members {
+					members[j] = spawn(typ, int64(len(st.entities)+j))
+				}
+				enemies.FormPack(members, pp, floatgeom.Point2{fieldX.Poll(), fieldY.Poll()}, st.rng)
+				for _, e := range members {
+					// Keep the formation inside the hallway
+					e.SetPos(e.X(), math.Max(fieldTop, math.Min(fieldBottom, e.Y())))
+					st.entities = append(st.entities, e)
+				}
+				spawned += len(members)
+				continue
+			}
+			typ := alg.WeightedChooseOneSeeded(enemyDist, st.rng)
+			e := spawn(typ, int64(len(st.entities)))
 			e.SetPos(fieldX.Poll(), fieldY.Poll())
 			st.entities = append(st.entities, e)
+			spawned++
 		}
 	}
 
@@ -167,6 +229,8 @@ func (st *Tracker) Produce(delta int64) *Section {
 		for i := 0; i < plan.chestCount.Poll(); i++ {
 			ch := doodads.NewChest(int64(plan.chestRange.Poll()))
 			ch.SetPos(fieldX.Poll(), fieldY.Poll())
+			ch.SectionID = st.sectionsDeep
+			ch.Idx = int64(len(st.entities))
 			st.entities = append(st.entities, ch)
 		}
 	}
@@ -176,14 +240,41 @@ func (st *Tracker) Produce(delta int64) *Section {
 	// }
 
 	newSection := st.generate()
+	newSection.id = st.sectionsDeep
+	st.changeLock.Lock()
 	for _, c := range st.changes[newSection.id] {
 		newSection.ApplyChange(c)
 	}
+	st.changeLock.Unlock()
 
 	dlog.Info("Created a section: ", newSection.GetId(), " with seed of ", st.rng.Seed)
 	return newSection
 }
 
+// tensionAt returns the tension a depth was first generated at, taking the
+// director's current tension if it hasn't been generated yet
+func (st *Tracker) tensionAt(depth int64) float64 {
+	st.changeLock.Lock()
+	defer st.changeLock.Unlock()
+	tension, ok := st.tensions[depth]
+	if !ok {
+		tension = st.director.Tension()
+		st.tensions[depth] = tension
+	}
+	return tension
+}
+
 func (st *Tracker) UpdateHistory(sectionID int64, change Change) {
+	st.changeLock.Lock()
 	st.changes[sectionID] = append(st.changes[sectionID], change)
+	st.changeLock.Unlock()
+}
+
+// ApplyLive applies a change to a section on screen and remembers it. Doing both
+// at once keeps what the change adds at the same index when it is replayed.
+func (st *Tracker) ApplyLive(s *Section, change Change) {
+	st.changeLock.Lock()
+	s.ApplyLiveChange(change)
+	st.changes[s.id] = append(st.changes[s.id], change)
+	st.changeLock.Unlock()
 }
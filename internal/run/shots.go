@@ -0,0 +1,46 @@
+package run
+
+import (
+	"sync"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/entities/x/move"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/weekly87/internal/abilities"
+	"github.com/oakmound/weekly87/internal/characters"
+)
+
+// enemyShots are the projectiles enemies have fired. They aren't part of any section,
+// so the sections' entities stay as the section changes remember them, and instead
+// they are moved along with the party whenever it is teleported.
+type enemyShots struct {
+	sync.Mutex
+	shots []*abilities.Product
+}
+
+// add shots that were just fired
+func (es *enemyShots) add(chrs []characters.Character) {
+	es.Lock()
+	for _, chr := range chrs {
+		if prd, ok := chr.(*abilities.Product); ok {
+			es.shots = append(es.shots, prd)
+		}
+	}
+	es.Unlock()
+}
+
+// shift the shots still flying, forgetting those that have landed
+func (es *enemyShots) shift(x float64) {
+	es.Lock()
+	flying := es.shots[:0]
+	for _, prd := range es.shots {
+		if event.GetEntity(int(prd.CID)) == nil {
+			continue
+		}
+		move.ShiftX(prd, x)
+		prd.MoveParticles(floatgeom.Point2{x, 0})
+		flying = append(flying, prd)
+	}
+	es.shots = flying
+	es.Unlock()
+}
@@ -96,6 +96,18 @@ var Scene = scene.Scene{
 		)
 		fpsBtn := btn.New(showFps)
 
+		y += 50
+		showCombat := btn.And(
+			menus.BtnCfgB,
+			btn.Width(150),
+			btn.Height(32),
+			btn.Toggle(infR2.Copy(), infR1.Copy(),
+				&settings.Active.ShowCombatInfo),
+			btn.Pos(x, y),
+			btn.Text("Combat Info"),
+		)
+		combatBtn := btn.New(showCombat)
+
 		y += 50
 
 		sfxVolume := menus.NewSlider(0, x+sliderXOffset, y, sliderWidth, sliderHeight, 10, 20, nil,
@@ -142,6 +154,7 @@ var Scene = scene.Scene{
 			selector.VertArrowControl(),
 			selector.Spaces(
 				fpsBtn.GetSpace(),
+				combatBtn.GetSpace(),
 				sfxVolume.Space,
 				musicVolume.Space,
 				masterVolume.Space,
@@ -161,7 +174,10 @@ var Scene = scene.Scene{
 					if i == 0 {
 						fpsBtn.Trigger("MouseClickOn", nil)
 					}
-					if i == 4 {
+					if i == 1 {
+						combatBtn.Trigger("MouseClickOn", nil)
+					}
+					if i == 5 {
 						stayInMenu = false
 					}
 					return
@@ -172,11 +188,11 @@ var Scene = scene.Scene{
 					return
 				}
 				switch i {
-				case 1:
-					sfxVolume.Slide(change)
 				case 2:
-					musicVolume.Slide(change)
+					sfxVolume.Slide(change)
 				case 3:
+					musicVolume.Slide(change)
+				case 4:
 					masterVolume.Slide(change)
 				}
 			}),
@@ -21,7 +21,11 @@ func (s *Settings) Store() {
 
 // Load the settings from the filesystem
 func Load() {
-	s := &Settings{}
+	s := &Settings{
+		// Settings files from before this could be turned off don't have it,
+		// and decoding leaves what is missing alone
+		ShowCombatInfo: true,
+	}
 
 	f, err := os.Open(settingsFile)
 	if err != nil {
@@ -7,7 +7,9 @@ type Settings struct {
 	MusicVolume   float64 `json:"musicVolume"`
 	MasterVolume  float64 `json:"masterVolume"`
 	ShowFpsToggle bool    `json:"showFpsToggle"`
-	Debug         bool    `json:"debugOn,omitempty"`
+	// ShowCombatInfo shows enemy health bars and the damage attacks deal
+	ShowCombatInfo bool `json:"showCombatInfo"`
+	Debug          bool `json:"debugOn,omitempty"`
 }
 
 // Active settings for the game
@@ -54,6 +54,9 @@ func loadAudio() {
 		"cooldown":      loudSFX,
 		"nope1":         loudSFX,
 		"abilityReady1": softSFX,
+		"spearStab1":    softSFX,
+		"spearThrow1":   softSFX,
+		"spearCatch1":   loudSFX,
 	}
 	for s, f := range files {
 		a, err := audio.Get(s + ".wav")
@@ -0,0 +1,65 @@
+package timescale
+
+import (
+	"sync"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/render/particle"
+)
+
+type trackedSource struct {
+	src    *particle.Source
+	acc    float64
+	paused bool
+}
+
+var (
+	srcLock sync.Mutex
+	sources = map[*particle.Source]*trackedSource{}
+)
+
+// Start puts time back to normal and keeps tracked particle sources in step with it.
+// To be called at the start of each scene that uses time scaling.
+func Start() {
+	Reset()
+	srcLock.Lock()
+	sources = map[*particle.Source]*trackedSource{}
+	srcLock.Unlock()
+	event.GlobalBind(stepSources, "EnterFrame")
+}
+
+// TrackSource has a particle source only advance its particles as fast as time passes where it is
+func TrackSource(src *particle.Source) {
+	srcLock.Lock()
+	sources[src] = &trackedSource{src: src}
+	srcLock.Unlock()
+}
+
+// UntrackSource stops keeping a particle source in step, to be called before stopping it
+func UntrackSource(src *particle.Source) {
+	srcLock.Lock()
+	delete(sources, src)
+	srcLock.Unlock()
+}
+
+// stepSources pauses sources on frames where they would not have moved yet
+func stepSources(int, interface{}) int {
+	srcLock.Lock()
+	defer srcLock.Unlock()
+	for _, ts := range sources {
+		x, y := ts.src.Generator.GetPos()
+		ts.acc += At(floatgeom.Point2{x, y})
+		if ts.acc >= 1 {
+			ts.acc--
+			if ts.paused {
+				ts.src.UnPause()
+				ts.paused = false
+			}
+		} else if !ts.paused {
+			ts.src.Pause()
+			ts.paused = true
+		}
+	}
+	return 0
+}
@@ -0,0 +1,155 @@
+// Package timescale tracks how fast time passes in the game world, both globally
+// and within local fields, so that things can be slowed or frozen in time
+package timescale
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oakmound/oak/alg/floatgeom"
+)
+
+// A Field changes the passage of time within its bounds
+type Field interface {
+	Bounds() floatgeom.Rect2
+	// Scale is how fast time passes within the field, 1 is normal speed
+	Scale() float64
+	// Done reports that the field is gone as of the given game time and should
+	// no longer be tracked. It is called while time is locked, so it must not
+	// call back into this package.
+	Done(now time.Time) bool
+}
+
+var (
+	lock sync.Mutex
+
+	base = 1.0
+	// freezeUntil is the real time enemies are frozen until
+	freezeUntil time.Time
+	fields      []Field
+
+	// gameNow is the game time as of lastReal
+	gameNow  = time.Now()
+	lastReal = time.Now()
+	// enemyNow is the game time as enemies see it, which stands still while they are frozen
+	enemyNow = gameNow
+)
+
+// Reset puts time back to normal, clearing any fields and freezes
+func Reset() {
+	lock.Lock()
+	advance()
+	base = 1
+	freezeUntil = time.Time{}
+	fields = nil
+	enemyNow = gameNow
+	lock.Unlock()
+}
+
+// advance folds elapsed real time into game time, must be called while holding the lock
+func advance() {
+	real := time.Now()
+	gameNow = gameNow.Add(time.Duration(float64(real.Sub(lastReal)) * base))
+	from := lastReal
+	if from.Before(freezeUntil) {
+		// Enemies don't move while frozen
+		from = freezeUntil
+		if real.Before(from) {
+			from = real
+		}
+	}
+	enemyNow = enemyNow.Add(time.Duration(float64(real.Sub(from)) * base))
+	lastReal = real
+}
+
+// Now returns the current game time, which only advances as fast as the global time scale
+func Now() time.Time {
+	lock.Lock()
+	defer lock.Unlock()
+	advance()
+	return gameNow
+}
+
+// EnemyNow returns the current game time for enemies, which also stops while they are frozen
+func EnemyNow() time.Time {
+	lock.Lock()
+	defer lock.Unlock()
+	advance()
+	return enemyNow
+}
+
+// Since returns how much game time has passed since t
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// Scale returns how fast time is passing globally, 1 is normal speed
+func Scale() float64 {
+	lock.Lock()
+	defer lock.Unlock()
+	return base
+}
+
+// SetScale sets the global time scale
+func SetScale(s float64) {
+	lock.Lock()
+	advance()
+	base = s
+	lock.Unlock()
+}
+
+// Freeze stops time for enemies and what they fire for the given real duration.
+// The party keeps moving, and its cooldowns keep counting down.
+func Freeze(dur time.Duration) {
+	lock.Lock()
+	advance()
+	if until := time.Now().Add(dur); until.After(freezeUntil) {
+		freezeUntil = until
+	}
+	lock.Unlock()
+}
+
+// AddField starts tracking a local time field
+func AddField(f Field) {
+	lock.Lock()
+	fields = append(fields, f)
+	lock.Unlock()
+}
+
+// At returns how fast time is passing at the given point, taking both
+// the global time scale and the slowest field covering the point into account
+func At(pt floatgeom.Point2) float64 {
+	lock.Lock()
+	defer lock.Unlock()
+	advance()
+	return base * localScale(pt)
+}
+
+// EnemyAt is At for enemies and what they fire, for whom time stops while frozen
+func EnemyAt(pt floatgeom.Point2) float64 {
+	lock.Lock()
+	defer lock.Unlock()
+	if time.Now().Before(freezeUntil) {
+		return 0
+	}
+	advance()
+	return base * localScale(pt)
+}
+
+// localScale returns the scale of the slowest field covering the point, dropping
+// fields that are done. Must be called while holding the lock.
+func localScale(pt floatgeom.Point2) float64 {
+	local := 1.0
+	kept := fields[:0]
+	for _, f := range fields {
+		if f.Done(gameNow) {
+			continue
+		}
+		kept = append(kept, f)
+		if f.Bounds().Contains(pt) && f.Scale() < local {
+			local = f.Scale()
+		}
+	}
+	fields = kept
+	return local
+}
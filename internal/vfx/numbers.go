@@ -0,0 +1,136 @@
+package vfx
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/oakmound/oak/event"
+	"github.com/oakmound/oak/render"
+	"github.com/oakmound/weekly87/internal/layer"
+	"github.com/oakmound/weekly87/internal/restrictor"
+)
+
+const (
+	// maxFloaters caps how many numbers can float at once, past it new ones are dropped
+	maxFloaters  = 40
+	floaterLife  = 40
+	floaterRise  = .75
+	floaterW     = 40
+	floaterH     = 12
+	floaterFontH = 12
+)
+
+// A floater is text that rises for a moment then disappears, reused once it is gone
+type floater struct {
+	txt *render.Text
+	c   color.RGBA
+	// gen changes each time the floater is reused, so a stale handle can't take it back
+	gen int
+}
+
+// floatHandle is one use of a floater, given to the restrictor to cull if it leaves the screen
+type floatHandle struct {
+	f   *floater
+	gen int
+}
+
+func (h floatHandle) GetPos() (float64, float64) {
+	return h.f.txt.X(), h.f.txt.Y()
+}
+
+func (h floatHandle) GetDims() (int, int) {
+	return floaterW, floaterH
+}
+
+// Destroy the floater early if it is still this handle's
+func (h floatHandle) Destroy() {
+	floaters.release(h.f, h.gen)
+}
+
+type floatPool struct {
+	sync.Mutex
+	fonts map[color.RGBA]*render.Font
+	// free floaters by their color, as each color needs its own font
+	free  map[color.RGBA][]*floater
+	count int
+}
+
+var floaters = &floatPool{
+	fonts: map[color.RGBA]*render.Font{},
+	free:  map[color.RGBA][]*floater{},
+}
+
+// get a floater of the color, making one if there is room
+func (fp *floatPool) get(c color.RGBA) *floater {
+	fp.Lock()
+	defer fp.Unlock()
+	if free := fp.free[c]; len(free) > 0 {
+		f := free[len(free)-1]
+		fp.free[c] = free[:len(free)-1]
+		return f
+	}
+	if fp.count >= maxFloaters {
+		return nil
+	}
+	fnt, ok := fp.fonts[c]
+	if !ok {
+		g := render.DefFontGenerator.Copy()
+		g.Color = image.NewUniform(c)
+		g.Size = floaterFontH
+		fnt = g.Generate()
+		fp.fonts[c] = fnt
+	}
+	fp.count++
+	return &floater{txt: fnt.NewStrText("", 0, 0), c: c}
+}
+
+// release a floater back to the pool, unless it has been reused since
+func (fp *floatPool) release(f *floater, gen int) {
+	fp.Lock()
+	defer fp.Unlock()
+	if f.gen != gen {
+		return
+	}
+	f.gen++
+	f.txt.Undraw()
+	fp.free[f.c] = append(fp.free[f.c], f)
+}
+
+// Float shows text rising from a point on the effect layer, like the damage an attack dealt
+func Float(s string, c color.RGBA, x, y float64) {
+	f := floaters.get(c)
+	if f == nil {
+		return
+	}
+	gen := f.gen
+	f.txt.SetString(s)
+	f.txt.SetPos(x, y)
+	render.Draw(f.txt, layer.Effect, 10)
+	restrictor.Add(floatHandle{f, gen})
+
+	frames := 0
+	event.GlobalBind(func(int, interface{}) int {
+		floaters.Lock()
+		stale := f.gen != gen
+		floaters.Unlock()
+		if stale {
+			return event.UnbindSingle
+		}
+		frames++
+		if frames > floaterLife {
+			floaters.release(f, gen)
+			return event.UnbindSingle
+		}
+		f.txt.ShiftY(-floaterRise)
+		return 0
+	}, "EnterFrame")
+}
+
+// ResetFloaters forgets every floater, for when a scene ends and takes them with it
+func ResetFloaters() {
+	floaters.Lock()
+	floaters.free = map[color.RGBA][]*floater{}
+	floaters.count = 0
+	floaters.Unlock()
+}
@@ -52,6 +52,22 @@ var (
 		)
 	}
 
+	// Burst is a quick ring in the given color
+	Burst = func(c color.RGBA) particle.Generator {
+		end := c
+		end.A = 0
+		return particle.NewColorGenerator(
+			particle.Color(c, color.RGBA{}, end, color.RGBA{}),
+			particle.Shape(shape.Diamond),
+			particle.Size(intrange.NewLinear(4, 9)),
+			particle.EndSize(intrange.NewConstant(2)),
+			particle.LifeSpan(floatrange.NewConstant(16)),
+			particle.Speed(floatrange.NewLinear(3, 5)),
+			particle.Angle(floatrange.NewLinear(0, 360)),
+			particle.NewPerFrame(floatrange.NewConstant(30)),
+		)
+	}
+
 	WhiteRing = func() particle.Generator {
 		return particle.NewColorGenerator(
 			particle.Color(